@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type langRange struct {
+	tag string
+	q   float64
+}
+
+// Match returns the best matching language code from the list of available codes
+// for the given HTTP Accept-Language header, eg: `en-US,en;q=0.9,fr;q=0.8`.
+// Language ranges are matched in the order of their quality values, and a range
+// with a region that isn't available falls back to its base language (en-US => en).
+// An empty string is returned if there are no matches.
+func Match(header string, available []string) string {
+	for _, r := range parseAcceptLanguage(header) {
+		if r.tag == "*" {
+			if len(available) > 0 {
+				return available[0]
+			}
+			continue
+		}
+
+		// Exact match, then progressively shorter prefixes: zh-hant-tw => zh-hant => zh.
+		for tag := r.tag; tag != ""; {
+			for _, a := range available {
+				if strings.EqualFold(a, tag) {
+					return a
+				}
+			}
+
+			n := strings.LastIndex(tag, "-")
+			if n < 0 {
+				break
+			}
+			tag = tag[:n]
+		}
+
+		// A base language range matches any of its available regions (en => en-GB).
+		for _, a := range available {
+			if strings.HasPrefix(strings.ToLower(a), r.tag+"-") {
+				return a
+			}
+		}
+	}
+
+	return ""
+}
+
+// Match returns the language in the bundle that best matches the given
+// HTTP Accept-Language header, or the default language if there are no matches.
+// The * range matches the default language, if there's one.
+func (b *Bundle) Match(header string) (*I18n, bool) {
+	codes := b.Codes()
+
+	b.mu.RLock()
+	def := b.def
+	b.mu.RUnlock()
+
+	// The default is the first available code so that * matches it.
+	for n, c := range codes {
+		if c == def {
+			copy(codes[1:n+1], codes[:n])
+			codes[0] = def
+			break
+		}
+	}
+
+	if code := Match(header, codes); code != "" {
+		return b.Get(code)
+	}
+
+	return b.Default()
+}
+
+// parseAcceptLanguage parses an Accept-Language header into a list of
+// lowercased language ranges sorted by their quality values. Ranges with
+// q=0 or invalid q values are dropped.
+func parseAcceptLanguage(header string) []langRange {
+	var out []langRange
+	for _, p := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(p), ";")
+		tag = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), "_", "-")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(k) != "q" {
+				continue
+			}
+
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || f < 0 || f > 1 {
+				q = 0
+			} else {
+				q = f
+			}
+		}
+
+		if q > 0 {
+			out = append(out, langRange{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(out, func(a, b int) bool {
+		return out[a].q > out[b].q
+	})
+
+	return out
+}
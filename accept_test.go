@@ -0,0 +1,45 @@
+package i18n
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	avail := []string{"en", "fr", "pt-BR", "zh-Hant"}
+
+	assert(t, Match("en-US,en;q=0.9,fr;q=0.8", avail), "en")
+	assert(t, Match("fr;q=0.8, en;q=0.9", avail), "en")
+	assert(t, Match("de,fr;q=0.5", avail), "fr")
+	assert(t, Match("pt", avail), "pt-BR")
+	assert(t, Match("pt-br", avail), "pt-BR")
+	assert(t, Match("zh-Hant-TW", avail), "zh-Hant")
+	assert(t, Match("en;q=0,fr;q=0.1", avail), "fr")
+	assert(t, Match("de, *;q=0.1", avail), "en")
+	assert(t, Match("de", avail), "")
+	assert(t, Match("", avail), "")
+	assert(t, Match("en;q=abc", avail), "")
+
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	fr, _ := New([]byte(`{"_.code": "fr", "_.name": "French"}`))
+
+	b := NewBundle()
+	b.Add(en)
+	b.Add(fr)
+
+	i, ok := b.Match("fr-CA,en;q=0.5")
+	assert(t, ok, true)
+	assert(t, i.Code(), "fr")
+
+	_, ok = b.Match("de")
+	assert(t, ok, false)
+
+	_ = b.SetDefault("en")
+	i, ok = b.Match("de")
+	assert(t, ok, true)
+	assert(t, i.Code(), "en")
+
+	// * matches the default language.
+	_ = b.SetDefault("fr")
+	i, _ = b.Match("de, *;q=0.5")
+	assert(t, i.Code(), "fr")
+	i, _ = b.Match("en, *;q=0.5")
+	assert(t, i.Code(), "en")
+}
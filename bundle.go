@@ -0,0 +1,123 @@
+package i18n
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Bundle holds multiple I18n language instances keyed by their language codes
+// with one of them optionally set as the default language.
+type Bundle struct {
+	langs map[string]*I18n
+	def   string
+
+	mu sync.RWMutex
+}
+
+// NewBundle returns a new empty Bundle.
+func NewBundle() *Bundle {
+	return &Bundle{
+		langs: make(map[string]*I18n),
+	}
+}
+
+// Add adds an I18n instance to the bundle keyed by its Code(), replacing
+// any existing instance with the same code. Regional variants without a fallback,
+// eg: en-GB, inherit from their base language in the bundle, eg: en, with it as
+// the fallback (see NewVariant()), regardless of the order in which they're added.
+func (b *Bundle) Add(i *I18n) {
+	code := i.Code()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	old := b.langs[code]
+	b.langs[code] = i
+
+	for c, l := range b.langs {
+		switch {
+		case l == i:
+		case isVariantOf(code, c) && strings.EqualFold(c, baseCode(code)):
+			if i.getFallback() == nil {
+				i.SetFallback(l)
+			}
+		case isVariantOf(c, code) && strings.EqualFold(code, baseCode(c)):
+			// Variants of the replaced base language switch to the new one.
+			if fb := l.getFallback(); fb == nil || (old != nil && fb == old) {
+				l.SetFallback(i)
+			}
+		}
+	}
+}
+
+// Get returns the I18n instance for the given language code.
+func (b *Bundle) Get(code string) (*I18n, bool) {
+	b.mu.RLock()
+	i, ok := b.langs[code]
+	b.mu.RUnlock()
+
+	return i, ok
+}
+
+// Codes returns the sorted list of language codes in the bundle.
+func (b *Bundle) Codes() []string {
+	b.mu.RLock()
+	out := make([]string, 0, len(b.langs))
+	for c := range b.langs {
+		out = append(out, c)
+	}
+	b.mu.RUnlock()
+
+	sort.Strings(out)
+	return out
+}
+
+// SetDefault sets the default language that's used when a requested language
+// code isn't in the bundle. The language should already have been added.
+func (b *Bundle) SetDefault(code string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.langs[code]; !ok {
+		return errors.New("unknown language: " + code)
+	}
+	b.def = code
+
+	return nil
+}
+
+// Default returns the default language instance, if one is set.
+func (b *Bundle) Default() (*I18n, bool) {
+	b.mu.RLock()
+	i, ok := b.langs[b.def]
+	b.mu.RUnlock()
+
+	return i, ok
+}
+
+// T returns the translation string for the given key in the given language.
+// If the language isn't in the bundle, the default language is used. If there's
+// no default language either, the key is returned.
+func (b *Bundle) T(code, key string) string {
+	i, ok := b.lang(code)
+	if !ok {
+		return key
+	}
+
+	return i.T(key)
+}
+
+// lang returns the instance for the given code or the default instance.
+func (b *Bundle) lang(code string) (*I18n, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if i, ok := b.langs[code]; ok {
+		return i, true
+	}
+
+	i, ok := b.langs[b.def]
+	return i, ok
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import "testing"
+
+func TestBundle(t *testing.T) {
+	en, err := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	de, err := New([]byte(`{"_.code": "de", "_.name": "German", "foo": "Fu"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBundle()
+	assert(t, b.T("en", "foo"), "foo")
+
+	b.Add(en)
+	b.Add(de)
+
+	assert(t, b.Codes(), []string{"de", "en"})
+	assert(t, b.T("de", "foo"), "Fu")
+	assert(t, b.T("en", "foo"), "Foo")
+	assert(t, b.T("fr", "foo"), "foo")
+
+	if err := b.SetDefault("fr"); err == nil {
+		t.Fatal("expected error for unknown default language")
+	}
+	if err := b.SetDefault("en"); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, b.T("fr", "foo"), "Foo")
+
+	i, ok := b.Get("de")
+	assert(t, ok, true)
+	assert(t, i.Name(), "German")
+
+	_, ok = b.Get("fr")
+	assert(t, ok, false)
+
+	i, ok = b.Default()
+	assert(t, ok, true)
+	assert(t, i.Code(), "en")
+}
+
+func TestBundleVariants(t *testing.T) {
+	gb, _ := New([]byte(`{"_.code": "en-GB", "_.name": "English (UK)", "color": "Colour"}`))
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "color": "Color", "center": "Center"}`))
+	au, _ := New([]byte(`{"_.code": "en-AU", "_.name": "English (AU)", "center": "Centre"}`))
+	de, _ := New([]byte(`{"_.code": "de-DE", "_.name": "German"}`))
+
+	b := NewBundle()
+	b.Add(gb)
+	b.Add(en)
+	b.Add(au)
+	b.Add(de)
+
+	assert(t, b.T("en-GB", "color"), "Colour")
+	assert(t, b.T("en-GB", "center"), "Center")
+	assert(t, b.T("en-AU", "color"), "Color")
+	assert(t, b.T("en-AU", "center"), "Centre")
+	assert(t, b.T("de-DE", "color"), "color")
+
+	// Variants switch to a replaced base language.
+	en2, _ := New([]byte(`{"_.code": "en", "_.name": "English", "center": "Middle"}`))
+	b.Add(en2)
+	assert(t, b.T("en-GB", "center"), "Middle")
+
+	// Variants with their own fallback retain it.
+	nz, _ := New([]byte(`{"_.code": "en-NZ", "_.name": "English (NZ)"}`))
+	nz.SetFallback(au)
+	b.Add(nz)
+	assert(t, b.T("en-NZ", "center"), "Centre")
+}
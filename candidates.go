@@ -0,0 +1,168 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// candSourceDefault is the source of candidates that don't specify one.
+const candSourceDefault = "machine"
+
+// Candidate is one of the candidate translations of a key, eg: from different
+// translators or machine translation services in a review workflow. In JSON
+// language maps, the candidates of a key are an array of objects or strings, eg:
+// "save": [{"text": "Save", "source": "human", "winner": true}, "Store"], where
+// a string is a candidate with only the text.
+type Candidate struct {
+	Text string `json:"text"`
+
+	// Source of the candidate, eg: human or machine, which is the default.
+	Source string `json:"source,omitempty"`
+
+	// Whether it's the active candidate that's used as the translation.
+	Winner bool `json:"winner,omitempty"`
+}
+
+// decodeCandidates decodes the candidates array of the key k from the decoder
+// (after its opening bracket) and returns the candidates with exactly one winner,
+// which is the first one marked as the winner, or the first one.
+func decodeCandidates(dec *json.Decoder, k string) ([]Candidate, error) {
+	var (
+		out    []Candidate
+		winner = -1
+	)
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		var c Candidate
+		if err := json.Unmarshal(raw, &c.Text); err != nil {
+			if err := json.Unmarshal(raw, &c); err != nil {
+				return nil, fmt.Errorf("invalid candidate for %s: %s", k, raw)
+			}
+		}
+		if c.Source == "" {
+			c.Source = candSourceDefault
+		}
+		if c.Winner && winner < 0 {
+			winner = len(out)
+		}
+
+		out = append(out, c)
+	}
+
+	// Closing bracket.
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("invalid value for %s: no candidates", k)
+	}
+
+	if winner < 0 {
+		winner = 0
+	}
+	for n := range out {
+		out[n].Winner = n == winner
+	}
+
+	return out, nil
+}
+
+// winner returns the winner of the given candidates.
+func winner(c []Candidate) Candidate {
+	for _, w := range c {
+		if w.Winner {
+			return w
+		}
+	}
+
+	return c[0]
+}
+
+// winnerSources sets the sources of the winners of the given candidates of keys
+// as the sources of the keys in srcs, which is returned.
+func winnerSources(srcs map[string]string, cands map[string][]Candidate) map[string]string {
+	if len(cands) == 0 {
+		return srcs
+	}
+
+	if srcs == nil {
+		srcs = make(map[string]string, len(cands))
+	}
+	for k, c := range cands {
+		srcs[k] = winner(c).Source
+	}
+
+	return srcs
+}
+
+// Candidates returns the candidate translations of the given key in the JSON
+// language map, with the winner that's used as the translation marked, or nil
+// if the key has a plain string value.
+func (i *I18n) Candidates(key string) []Candidate {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	c, ok := i.cands[key]
+	if !ok {
+		return nil
+	}
+
+	return append([]Candidate(nil), c...)
+}
+
+// SetWinner sets the candidate at the index idx in Candidates() as the winner,
+// whose text is then used as the translation of the key and whose source is
+// returned by Source(). An error is returned if the key has no candidates or
+// the index is out of range.
+func (i *I18n) SetWinner(key string, idx int) error {
+	i.mu.Lock()
+	cands, ok := i.cands[key]
+	if !ok {
+		i.mu.Unlock()
+		return fmt.Errorf("%s has no candidates", key)
+	}
+	if idx < 0 || idx >= len(cands) {
+		i.mu.Unlock()
+		return fmt.Errorf("invalid candidate index for %s: %d", key, idx)
+	}
+
+	c := make([]Candidate, len(cands))
+	for n := range cands {
+		c[n] = cands[n]
+		c[n].Winner = n == idx
+	}
+	i.cands[key] = c
+
+	if i.srcs == nil {
+		i.srcs = make(map[string]string)
+	}
+	i.srcs[key] = c[idx].Source
+
+	m, ci := i.copyMaps()
+	m[key] = i.normalize(key, c[idx].Text)
+	i.langMap, i.ciIndex = m, ci
+	i.mu.Unlock()
+	i.resetPluralCache()
+
+	return nil
+}
+
+// setCandidates sets the given candidates of keys, and removes the candidates
+// of the other keys in l that have plain values. It should be called with the
+// lock held.
+func (i *I18n) setCandidates(cands map[string][]Candidate, l map[string]string) {
+	for k := range l {
+		if c, ok := cands[k]; ok {
+			if i.cands == nil {
+				i.cands = make(map[string][]Candidate)
+			}
+			i.cands[k] = c
+		} else {
+			delete(i.cands, k)
+		}
+	}
+}
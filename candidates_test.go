@@ -0,0 +1,64 @@
+package i18n
+
+import "testing"
+
+func TestCandidates(t *testing.T) {
+	i, err := New([]byte(`{"_.code": "en", "_.name": "English",
+		"save": ["Store", {"text": "Save", "source": "human", "winner": true}],
+		"btn": {"cancel": [{"text": "Abort", "source": "deepl"}, "Cancel"]},
+		"ok": "OK"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.T("save"), "Save")
+	assert(t, i.Source("save"), "human")
+	assert(t, i.Candidates("save"), []Candidate{{"Store", "machine", false}, {"Save", "human", true}})
+	assert(t, i.T("btn.cancel"), "Abort")
+	assert(t, i.Source("btn.cancel"), "deepl")
+	assert(t, i.Candidates("ok"), []Candidate(nil))
+	assert(t, i.Keys(), []string{"btn.cancel", "ok", "save"})
+
+	assert(t, i.SetWinner("btn.cancel", 1), nil)
+	assert(t, i.T("btn.cancel"), "Cancel")
+	assert(t, i.Source("btn.cancel"), "machine")
+	assert(t, i.Candidates("btn.cancel"), []Candidate{{"Abort", "deepl", false}, {"Cancel", "machine", true}})
+	assert(t, i.SetWinner("btn.cancel", 2), "invalid candidate index for btn.cancel: 2")
+	assert(t, i.SetWinner("ok", 0), "ok has no candidates")
+
+	c := i.Clone()
+	assert(t, c.SetWinner("save", 0), nil)
+	assert(t, c.T("save"), "Store")
+	assert(t, i.T("save"), "Save")
+
+	// Plain values replace the candidates.
+	assert(t, i.Load([]byte(`{"save": "Save!", "ok": [{"text": "Okay", "source": "human"}]}`)), nil)
+	assert(t, i.T("save"), "Save!")
+	assert(t, i.Candidates("save"), []Candidate(nil))
+	assert(t, i.T("ok"), "Okay")
+	assert(t, i.Candidates("ok"), []Candidate{{"Okay", "human", true}})
+
+	// Existing candidates are retained with the existing value.
+	keep := func(key, existing, incoming string) string { return existing }
+	assert(t, i.LoadWith([]byte(`{"ok": ["Ok", "Fine"]}`), keep), nil)
+	assert(t, i.T("ok"), "Okay")
+	assert(t, i.Candidates("ok"), []Candidate{{"Okay", "human", true}})
+
+	o, _ := New([]byte(`{"_.code": "en", "_.name": "English", "new": ["New", "Fresh"]}`))
+	_ = i.Merge(o)
+	assert(t, i.Candidates("new"), []Candidate{{"New", "machine", true}, {"Fresh", "machine", false}})
+
+	_ = i.Delete("new")
+	assert(t, i.Candidates("new"), []Candidate(nil))
+
+	// Keys that look internal are regular keys.
+	r, err := New([]byte(`{"_.code": "en", "_.name": "English", "_candidates": {"x": "hello"}, "y": ["Y"], "y": "Why"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, r.Has("_candidates.x"), true)
+	assert(t, r.T("_candidates.x"), "hello")
+	assert(t, r.Candidates("_candidates.x"), []Candidate(nil))
+	assert(t, r.Candidates("y"), []Candidate(nil))
+	assert(t, r.T("y"), "Why")
+}
@@ -0,0 +1,102 @@
+package i18n
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Placeholders for month and weekday names in Go time layouts that are replaced
+// with the names from the language map after formatting.
+const (
+	phMonth      = "\x01"
+	phMonthShort = "\x02"
+	phDay        = "\x03"
+	phDayShort   = "\x04"
+)
+
+var layoutNames = []struct {
+	token, ph string
+}{
+	// Longer tokens should precede their prefixes.
+	{"January", phMonth},
+	{"Monday", phDay},
+	{"Jan", phMonthShort},
+	{"Mon", phDayShort},
+}
+
+// FormatTime formats t with the Go time layout (eg: "02 Jan 2006") stored in the
+// language map under formatKey. By convention, layouts are stored under the
+// _.dateFormat.* meta keys, eg: _.dateFormat.short. If the key is missing,
+// formatKey itself is used as the layout.
+//
+// Month and weekday names are taken from the pipe separated lists in the
+// _.months (January to December) and _.weekdays (Sunday to Saturday) keys, and
+// their abbreviations from _.monthsShort and _.weekdaysShort. Missing
+// abbreviations are the first three characters of the names and missing names
+// are in English.
+func (i *I18n) FormatTime(t time.Time, formatKey string) string {
+	layout, _, ok := i.lookup(formatKey)
+	if !ok {
+		layout = formatKey
+	}
+
+	// Replace the name tokens in the layout with placeholders.
+	var b strings.Builder
+	for n := 0; n < len(layout); {
+		matched := false
+		for _, l := range layoutNames {
+			if strings.HasPrefix(layout[n:], l.token) {
+				b.WriteString(l.ph)
+				n += len(l.token)
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			b.WriteByte(layout[n])
+			n++
+		}
+	}
+
+	out := t.Format(b.String())
+	if !strings.ContainsAny(out, phMonth+phMonthShort+phDay+phDayShort) {
+		return out
+	}
+
+	var (
+		month = i.getName(i.metaKey("months"), i.metaKey("monthsShort"), 12, int(t.Month())-1, t.Month().String())
+		day   = i.getName(i.metaKey("weekdays"), i.metaKey("weekdaysShort"), 7, int(t.Weekday()), t.Weekday().String())
+	)
+
+	return strings.NewReplacer(phMonth, month[0], phMonthShort, month[1],
+		phDay, day[0], phDayShort, day[1]).Replace(out)
+}
+
+// getName returns the name and the short name at index idx from the pipe separated
+// lists of names in the given keys. The lists should have num names each.
+func (i *I18n) getName(key, shortKey string, num, idx int, def string) [2]string {
+	out := [2]string{def, def}
+
+	if s, _, ok := i.lookup(key); ok {
+		if names := strings.Split(s, "|"); len(names) == num {
+			out[0] = strings.TrimSpace(names[idx])
+			out[1] = out[0]
+		}
+	}
+
+	if s, _, ok := i.lookup(shortKey); ok {
+		if names := strings.Split(s, "|"); len(names) == num {
+			out[1] = strings.TrimSpace(names[idx])
+			return out
+		}
+	}
+
+	// Abbreviate the name.
+	if utf8.RuneCountInString(out[1]) > 3 {
+		out[1] = string([]rune(out[1])[:3])
+	}
+
+	return out
+}
@@ -0,0 +1,30 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	de, _ := New([]byte(`{
+		"_.code": "de",
+		"_.name": "German",
+		"_.dateFormat.short": "02.01.2006",
+		"_.dateFormat.long": "Monday, 2. January 2006",
+		"_.dateFormat.abbr": "Mon, 2. Jan",
+		"_.months": "Januar|Februar|März|April|Mai|Juni|Juli|August|September|Oktober|November|Dezember",
+		"_.weekdays": "Sonntag|Montag|Dienstag|Mittwoch|Donnerstag|Freitag|Samstag",
+		"_.weekdaysShort": "So|Mo|Di|Mi|Do|Fr|Sa"
+	}`))
+
+	d := time.Date(2023, time.March, 15, 10, 30, 0, 0, time.UTC)
+
+	assert(t, de.FormatTime(d, "_.dateFormat.short"), "15.03.2023")
+	assert(t, de.FormatTime(d, "_.dateFormat.long"), "Mittwoch, 15. März 2023")
+	assert(t, de.FormatTime(d, "_.dateFormat.abbr"), "Mi, 15. Mär")
+	assert(t, de.FormatTime(d, "2006-01-02 15:04"), "2023-03-15 10:30")
+
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "_.dateFormat.long": "Monday, January 2, 2006"}`))
+	assert(t, en.FormatTime(d, "_.dateFormat.long"), "Wednesday, March 15, 2023")
+	assert(t, en.FormatTime(d, "Mon Jan 2"), "Wed Mar 15")
+}
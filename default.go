@@ -0,0 +1,72 @@
+package i18n
+
+import "sync"
+
+// The package level default instance (see SetDefault()).
+var (
+	defaultI18n   *I18n
+	defaultI18nMu sync.RWMutex
+)
+
+// SetDefault sets the default instance that the package level translation
+// functions, T(), Ts(), Tc(), S(), and P(), use, eg: for small programs that
+// don't pass an instance around. It's safe to call concurrently with the
+// functions. Passing nil unsets the default instance.
+func SetDefault(i *I18n) {
+	defaultI18nMu.Lock()
+	defaultI18n = i
+	defaultI18nMu.Unlock()
+}
+
+// Default returns the default instance set with SetDefault(), or nil.
+func Default() *I18n {
+	defaultI18nMu.RLock()
+	defer defaultI18nMu.RUnlock()
+
+	return defaultI18n
+}
+
+// T is T() on the default instance. It returns the key if there's no default instance.
+func T(key string) string {
+	if i := Default(); i != nil {
+		return i.T(key)
+	}
+
+	return key
+}
+
+// Ts is Ts() on the default instance. It returns the key if there's no default instance.
+func Ts(key string, params ...interface{}) string {
+	if i := Default(); i != nil {
+		return i.Ts(key, params...)
+	}
+
+	return key
+}
+
+// Tc is Tc() on the default instance. It returns the key if there's no default instance.
+func Tc(key string, n int) string {
+	if i := Default(); i != nil {
+		return i.Tc(key, n)
+	}
+
+	return key
+}
+
+// S is S() on the default instance. It returns the key if there's no default instance.
+func S(key string) string {
+	if i := Default(); i != nil {
+		return i.S(key)
+	}
+
+	return key
+}
+
+// P is P() on the default instance. It returns the key if there's no default instance.
+func P(key string) string {
+	if i := Default(); i != nil {
+		return i.P(key)
+	}
+
+	return key
+}
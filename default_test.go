@@ -0,0 +1,40 @@
+package i18n
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetDefault(t *testing.T) {
+	assert(t, Default() == nil, true)
+	assert(t, T("hello"), "hello")
+	assert(t, Ts("greet", "name", "John"), "greet")
+	assert(t, Tc("page", 2), "page")
+	assert(t, S("page"), "page")
+	assert(t, P("page"), "page")
+
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "hello": "Hello", "greet": "Hi {name}", "page": "{n} page|{n} pages"}`))
+	SetDefault(i)
+	defer SetDefault(nil)
+
+	assert(t, Default() == i, true)
+	assert(t, T("hello"), "Hello")
+	assert(t, Ts("greet", "name", "John"), "Hi John")
+	assert(t, Tc("page", 2), "2 pages")
+	assert(t, S("page"), "{n} page")
+	assert(t, P("page"), "{n} pages")
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			SetDefault(i)
+			_ = T("hello")
+		}()
+	}
+	wg.Wait()
+
+	SetDefault(nil)
+	assert(t, T("hello"), "hello")
+}
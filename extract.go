@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// extractFuncs maps the names of the translation functions to the index of
+// their key argument for ExtractKeys().
+var extractFuncs = map[string]int{
+	"T": 0, "Ts": 0, "TsE": 0, "Tsm": 0, "Tsp": 0, "TSelect": 0,
+	"TDefault": 0, "TsDefault": 0, "Tc": 0, "Tcs": 0, "Tcf": 0, "Tco": 0, "TcKey": 0,
+	"TsD": 0, "TICU": 0, "S": 0, "P": 0, "Forms": 0, "Lookup": 0, "FT": 1,
+}
+
+// ExtractKeys parses the given Go source files, and the .go files in the given
+// directories recursively, and returns the sorted list of distinct keys passed as
+// string literals to the translation functions, eg: i.T("pageTitle") and
+// i.Tc("page", n), to cross-check the keys used in code against a language map,
+// eg: with Has() at build time. TMap() keys and the contextual keys of TCtx(),
+// eg: verb|post for TCtx("verb", "post"), along with the keys they fall back
+// to, eg: post, are also extracted. Keys that are not string literals, eg:
+// variables, are ignored.
+//
+// As the source isn't type checked, calls to methods and functions that have the
+// names of the translation functions are considered, whether they're qualified,
+// eg: i.T() or i18n.T(), or not, eg: T() in the package or with a dot import.
+// vendor, testdata, and hidden directories are skipped.
+func ExtractKeys(paths ...string) ([]string, error) {
+	var (
+		fset = token.NewFileSet()
+		keys = map[string]bool{}
+	)
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				name := d.Name()
+				if path != p && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if path != p && !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+
+			f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return err
+			}
+			extractFileKeys(f, keys)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]string, 0, len(keys))
+	for k := range keys {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
+
+// extractFileKeys adds the keys in the translation function calls in f to keys.
+func extractFileKeys(f *ast.File, keys map[string]bool) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		var name string
+		switch fn := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			name = fn.Sel.Name
+		case *ast.Ident:
+			name = fn.Name
+		default:
+			return true
+		}
+
+		switch name {
+		case "TMap", "TMapFound":
+			for _, a := range call.Args {
+				if k, ok := stringLit(a); ok {
+					keys[k] = true
+				}
+			}
+
+		case "TCtx":
+			if len(call.Args) != 2 {
+				break
+			}
+			ctx, ok := stringLit(call.Args[0])
+			if !ok {
+				break
+			}
+			if k, ok := stringLit(call.Args[1]); ok {
+				keys[ctx+"|"+k] = true
+				keys[k] = true
+			}
+
+		default:
+			idx, ok := extractFuncs[name]
+			if !ok || idx >= len(call.Args) {
+				break
+			}
+			if k, ok := stringLit(call.Args[idx]); ok {
+				keys[k] = true
+			}
+		}
+
+		return true
+	})
+}
+
+// stringLit returns the value of a string literal expression.
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractKeys(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+func main() {
+	key := "dynamic"
+	i.T("pageTitle")
+	i.Ts("pageVars", "name", "Foo")
+	i.Tc(` + "`page`" + `, 2)
+	i.TCtx("verb", "post")
+	i.TMap("save", "cancel", key)
+	i.FT(w, "footer")
+	i.T(key)
+	fmt.Println("notAKey")
+	T("bare")
+	Tc("dotPage", 1)
+	func() {}()
+}
+`
+	for path, b := range map[string]string{
+		"main.go":             src,
+		"sub/sub.go":          "package sub\n\nvar s = i18n.Tsm(\"sub.title\", nil)\n",
+		"vendor/v.go":         "package v\n\nvar s = i.T(\"vendored\")\n",
+		"sub/notes.txt":       `i.T("text")`,
+		"testdata/invalid.go": "package",
+	} {
+		path = filepath.Join(dir, path)
+		_ = os.MkdirAll(filepath.Dir(path), 0700)
+		if err := os.WriteFile(path, []byte(b), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := ExtractKeys(dir)
+	assert(t, err, nil)
+	assert(t, keys, []string{"bare", "cancel", "dotPage", "footer", "page", "pageTitle", "pageVars", "post", "save", "sub.title", "verb|post"})
+
+	keys, _ = ExtractKeys(filepath.Join(dir, "sub", "sub.go"))
+	assert(t, keys, []string{"sub.title"})
+
+	_, err = ExtractKeys(filepath.Join(dir, "testdata"))
+	assert(t, err != nil, true)
+}
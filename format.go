@@ -0,0 +1,199 @@
+package i18n
+
+import (
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// MoneyValue is a currency amount param value. See Money().
+type MoneyValue struct {
+	Amount   float64
+	Currency string
+}
+
+// PercentValue is a percentage param value. See Percent().
+type PercentValue float64
+
+// currencySuffix is the list of languages in which the currency symbol
+// succeeds the amount, eg: 1.234,50 € in German.
+var currencySuffix = map[string]bool{
+	"de": true, "fr": true, "es": true, "it": true, "pt": true, "ru": true,
+	"pl": true, "cs": true, "sk": true, "sv": true, "fi": true, "nb": true,
+	"no": true, "da": true, "hu": true, "ro": true, "lt": true, "lv": true,
+	"et": true, "sl": true, "hr": true, "sr": true, "bg": true, "uk": true,
+	"be": true, "el": true, "is": true, "ca": true, "vi": true,
+}
+
+var enPrinter = message.NewPrinter(language.English)
+
+// Money returns a currency amount param value for an ISO 4217 currency code that
+// Ts() and the other substitution functions format as per the instance's
+// language, eg: $1,234.50 for en and 1.234,50 € for de.
+// eg: Ts("priceMsg", "price", i18n.Money(1234.5, "USD"))
+func Money(amount float64, currency string) MoneyValue {
+	return MoneyValue{Amount: amount, Currency: currency}
+}
+
+// Percent returns a percentage param value for a fraction, eg: 0.155 for 15.5%,
+// that Ts() and the other substitution functions format as per the instance's
+// language, eg: 15.5% for en, 15,5 % for fr, and %15,5 for tr. Up to two
+// fraction digits are retained.
+// eg: Ts("discountMsg", "discount", i18n.Percent(0.155))
+func Percent(v float64) PercentValue {
+	return PercentValue(v)
+}
+
+// String returns the percentage formatted in English.
+func (p PercentValue) String() string {
+	return p.format(enPrinter)
+}
+
+// format formats the percentage with the given printer.
+func (p PercentValue) format(pr *message.Printer) string {
+	return pr.Sprint(number.Percent(float64(p), number.MaxFractionDigits(2)))
+}
+
+// String returns the amount formatted in English.
+func (m MoneyValue) String() string {
+	return m.format(enPrinter, "en")
+}
+
+// format formats the amount with the given printer and language's symbol placement.
+func (m MoneyValue) format(p *message.Printer, code string) string {
+	amount, sign := m.Amount, ""
+	if amount < 0 {
+		amount, sign = -amount, "-"
+	}
+
+	u, err := currency.ParseISO(m.Currency)
+	if err != nil {
+		return sign + p.Sprint(number.Decimal(amount, number.Scale(2))) + " " + m.Currency
+	}
+
+	scale, _ := currency.Standard.Rounding(u)
+	var (
+		amt = p.Sprint(number.Decimal(amount, number.Scale(scale)))
+		sym = p.Sprint(currency.Symbol(u))
+	)
+
+	if currencySuffix[baseCode(code)] {
+		return sign + amt + " " + sym
+	}
+	return sign + sym + amt
+}
+
+// SetNumberFormat sets whether integer and float param values substituted by
+// Ts() and the other substitution functions are formatted with the digit grouping
+// and decimal separators of the instance's language, eg: 1,234,567.5 for en
+// and 1.234.567,5 for de. String param values are not affected.
+func (i *I18n) SetNumberFormat(on bool) {
+	i.mu.Lock()
+	i.numFormat = on
+	i.mu.Unlock()
+}
+
+// SetStringer sets an optional function that formats param values substituted by
+// Ts() and the other substitution functions, eg: to render time.Time values as
+// ISO 8601 or decimals with a fixed scale. If it returns false for a value, the
+// value is formatted as usual.
+func (i *I18n) SetStringer(fn func(v interface{}) (string, bool)) {
+	i.mu.Lock()
+	i.stringer = fn
+	i.mu.Unlock()
+}
+
+// formatValue returns the string representation of a param value formatting
+// currency values, and numeric values if numFormat is set, as per the
+// instance's language.
+//
+// Lists ([]string or []interface{}) are joined with the separator in the _.listSep
+// meta key (default ", ") and the conjunction in the _.listAnd key (default "and"),
+// eg: "a, b and c" in English and "a, b et c" in French.
+//
+// A stringer set with SetStringer() takes precedence over all of these. The
+// list meta keys are looked up in the given view of the instance's maps, if
+// it's not nil (see lookupIn()).
+func (i *I18n) formatValue(v interface{}, numFormat bool, view *mapView) string {
+	i.mu.RLock()
+	fn := i.stringer
+	i.mu.RUnlock()
+
+	if fn != nil {
+		if s, ok := fn(v); ok {
+			return s
+		}
+	}
+
+	code, p := i.locale()
+
+	switch v := v.(type) {
+	case MoneyValue:
+		return v.format(p, code)
+	case PercentValue:
+		return v.format(p)
+	case []string:
+		return i.joinList(v, view)
+	case []interface{}:
+		items := make([]string, len(v))
+		for n, item := range v {
+			items[n] = i.formatValue(item, numFormat, view)
+		}
+		return i.joinList(items, view)
+	}
+
+	if numFormat && isNumber(v) {
+		return p.Sprint(number.Decimal(v))
+	}
+
+	return toString(v)
+}
+
+// isNumber returns true if v is an integer or a float. json.Numbers are
+// not considered numbers so that they're never reformatted.
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return true
+	}
+
+	return false
+}
+
+// joinList joins a list of strings with the language's list separator and
+// conjunction from the given view of the instance's maps, if it's not nil.
+func (i *I18n) joinList(items []string, view *mapView) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+
+	sep, _, ok := i.lookupIn(view, i.metaKey("listSep"))
+	if !ok {
+		sep = ", "
+	}
+	and, _, ok := i.lookupIn(view, i.metaKey("listAnd"))
+	if !ok {
+		and = "and"
+	}
+
+	return strings.Join(items[:len(items)-1], sep) + " " + and + " " + items[len(items)-1]
+}
+
+// SortStrings sorts the given strings, eg: translated labels of the options in a
+// dropdown, in place as per the collation rules of the instance's language, eg:
+// å, ä, and ö after z in Swedish, and ä with a in German. Case differences are
+// only considered between strings that are otherwise equal.
+func (i *I18n) SortStrings(ss []string) {
+	// Collators aren't safe for concurrent use.
+	c := collate.New(language.Make(i.Code()))
+	c.SortStrings(ss)
+}
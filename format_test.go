@@ -0,0 +1,125 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNumberFormat(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "count": "{n} items"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "count": "{n} Artikel"}`))
+
+	assert(t, en.Ts("count", "n", 1234567), "1234567 items")
+
+	en.SetNumberFormat(true)
+	de.SetNumberFormat(true)
+
+	assert(t, en.Ts("count", "n", 1234567), "1,234,567 items")
+	assert(t, en.Ts("count", "n", 1234.5), "1,234.5 items")
+	assert(t, en.Ts("count", "n", uint8(12)), "12 items")
+	assert(t, en.Ts("count", "n", "1234567"), "1234567 items")
+	assert(t, de.Ts("count", "n", 1234567), "1.234.567 Artikel")
+	assert(t, de.Ts("count", "n", -1234.5), "-1.234,5 Artikel")
+
+	en.SetNumberFormat(false)
+	assert(t, en.Ts("count", "n", 1234567), "1234567 items")
+}
+
+func TestMoney(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "priceMsg": "The price is {price}"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "priceMsg": "Der Preis ist {price}"}`))
+
+	assert(t, en.Ts("priceMsg", "price", Money(1234.5, "USD")), "The price is $1,234.50")
+	assert(t, en.Ts("priceMsg", "price", Money(-5, "USD")), "The price is -$5.00")
+	assert(t, en.Ts("priceMsg", "price", Money(1234.6, "JPY")), "The price is ¥1,235")
+	assert(t, de.Ts("priceMsg", "price", Money(1234.5, "EUR")), "Der Preis ist 1.234,50 €")
+	assert(t, de.Ts("priceMsg", "price", Money(1234.5, "XYZ1")), "Der Preis ist 1.234,50 XYZ1")
+	assert(t, Money(1234.5, "EUR").String(), "€1,234.50")
+}
+
+func TestLists(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "fruits": "I like {list}"}`))
+	fr, _ := New([]byte(`{"_.code": "fr", "_.name": "French", "_.listAnd": "et", "fruits": "J'aime {list}"}`))
+
+	assert(t, en.Ts("fruits", "list", []string{"apples", "oranges", "bananas"}), "I like apples, oranges and bananas")
+	assert(t, en.Ts("fruits", "list", []string{"apples", "oranges"}), "I like apples and oranges")
+	assert(t, en.Ts("fruits", "list", []string{"apples"}), "I like apples")
+	assert(t, en.Ts("fruits", "list", []string{}), "I like ")
+	assert(t, fr.Ts("fruits", "list", []interface{}{"pommes", 2, "bananes"}), "J'aime pommes, 2 et bananes")
+
+	en.SetNumberFormat(true)
+	assert(t, en.Ts("fruits", "list", []interface{}{1000, 2000}), "I like 1,000 and 2,000")
+}
+
+func TestPluralNumberFormat(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "files": "{count} file | {count} files", "summary": "You have {files}"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "files": "{count} Datei | {count} Dateien"}`))
+
+	assert(t, en.Tcs("files", 1234), "1234 files")
+
+	en.SetNumberFormat(true)
+	de.SetNumberFormat(true)
+
+	assert(t, en.Tcs("files", 1), "1 file")
+	assert(t, en.Tcs("files", 1000), "1,000 files")
+	assert(t, en.Tcs("files", 1234), "1,234 files")
+	assert(t, en.Tc("files", 1234), "1,234 files")
+	assert(t, en.Ts("summary", "files", "{files:1234}"), "You have 1,234 files")
+	assert(t, de.Tcs("files", 1), "1 Datei")
+	assert(t, de.Tcs("files", 1000), "1.000 Dateien")
+	assert(t, de.Tcs("files", -1), "-1 Datei")
+}
+
+func TestJSONNumber(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "amount": "Amount: {amount}", "items": "{n, plural, one {# item} other {# items}}"}`))
+
+	assert(t, i.Ts("amount", "amount", json.Number("12.340")), "Amount: 12.340")
+	assert(t, i.Ts("amount", "amount", json.Number("12345678901234567890")), "Amount: 12345678901234567890")
+
+	i.SetNumberFormat(true)
+	assert(t, i.Ts("amount", "amount", json.Number("12345678901234567890")), "Amount: 12345678901234567890")
+	assert(t, i.TICU("items", map[string]interface{}{"n": json.Number("1")}), "1 item")
+}
+
+func TestSetStringer(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "msg": "Updated {name} at {at}, {n} times"}`))
+	i.SetStringer(func(v interface{}) (string, bool) {
+		if t, ok := v.(time.Time); ok {
+			return t.Format(time.RFC3339), true
+		}
+		return "", false
+	})
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert(t, i.Ts("msg", "name", "doc", "at", at, "n", 3), "Updated doc at 2024-01-02T03:04:05Z, 3 times")
+
+	i.SetStringer(nil)
+	assert(t, i.Ts("msg", "name", "doc", "at", at, "n", 3), "Updated doc at "+at.String()+", 3 times")
+}
+
+func TestPercent(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "discountMsg": "{discount} discount"}`))
+	fr, _ := New([]byte(`{"_.code": "fr", "_.name": "French", "discountMsg": "{discount} de remise"}`))
+	tr, _ := New([]byte(`{"_.code": "tr", "_.name": "Turkish", "discountMsg": "{discount} indirim"}`))
+
+	assert(t, en.Ts("discountMsg", "discount", Percent(0.155)), "15.5% discount")
+	assert(t, en.Ts("discountMsg", "discount", Percent(0.5)), "50% discount")
+	assert(t, fr.Ts("discountMsg", "discount", Percent(0.155)), "15,5 % de remise")
+	assert(t, tr.Ts("discountMsg", "discount", Percent(0.15)), "%15 indirim")
+	assert(t, Percent(0.25).String(), "25%")
+}
+
+func TestSortStrings(t *testing.T) {
+	ss := []string{"öl", "zebra", "Apple", "ål", "apple", "äpple", "banana"}
+	for code, expected := range map[string][]string{
+		"sv": {"apple", "Apple", "banana", "zebra", "ål", "äpple", "öl"},
+		"de": {"ål", "apple", "Apple", "äpple", "banana", "öl", "zebra"},
+	} {
+		i, _ := New([]byte(`{"_.code": "` + code + `", "_.name": "Lang"}`))
+		s := append([]string(nil), ss...)
+		i.SortStrings(s)
+		assert(t, code+fmt.Sprint(s), code+fmt.Sprint(expected))
+	}
+}
@@ -1,3 +1,12 @@
 module github.com/knadh/go-i18n
 
 go 1.20
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/fsnotify/fsnotify v1.7.0
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.5.0 // indirect
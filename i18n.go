@@ -6,193 +6,2852 @@
 package i18n
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
 	"io/ioutil"
-	"regexp"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 // I18n enables simple translation functions over a language map.
 type I18n struct {
-	code    string `json:"code"`
-	name    string `json:"name"`
+	code    string
+	name    string
 	langMap map[string]string
+
+	// Keys in langMap in the order in which they were loaded, for JSONOrdered().
+	order []string
+
+	// mu guards langMap against concurrent Load()s and lookups.
+	//
+	// langMap and ciIndex are copy-on-write. A published map is never modified,
+	// and writes, eg: Set() and Reload(), swap in a modified copy with the lock
+	// held. A translation call pins the maps once (see mapView) and uses them for
+	// all its lookups, including the nested {key} references, so that it never
+	// sees a partially updated map or a mix of the maps before and after a write.
+	mu sync.RWMutex
+
+	// Fallback instance that's looked up when a key is missing.
+	fallback *I18n
+
+	// Whether TsE() returns ErrMissingKey for missing keys.
+	missingErr bool
+
+	// Whether TsE() returns ErrUnresolvedParams for unsubstituted {params}.
+	strict bool
+
+	// Whether substituted param values are HTML escaped.
+	htmlEscape bool
+
+	// Whether substituted param values are wrapped in bidi isolates.
+	bidiIsolate bool
+
+	// Locale printer for formatting numeric and currency param values,
+	// and whether numeric param values are formatted.
+	printer   *message.Printer
+	numFormat bool
+
+	// The file (in fsys, or on disk if fsys is nil) the instance
+	// was created from, for Reload().
+	path string
+	fsys fs.FS
+
+	// Optional callback that's invoked after every reload by Watch().
+	onReload func(err error)
+
+	// Optional callback that's invoked when a key is missing.
+	onMissing func(key, code string)
+
+	// Optional function that formats param values (see SetStringer()).
+	stringer func(v interface{}) (string, bool)
+
+	// Optional function that formats the string returned for missing keys.
+	missingFmt func(key string) string
+
+	// Params that are available to all substitutions (see SetGlobals()).
+	globals map[string]interface{}
+
+	// Optional resolver for keys that are missing in the language map.
+	resolver func(key string) (string, bool)
+
+	// Whitespace normalization of values (see SetNormalizeWhitespace()).
+	trimSpace, collapseSpace bool
+
+	// Whether empty and whitespace-only values are missing (see SetEmptyAsMissing()).
+	emptyMissing bool
+
+	// Lowercased key => key index for case insensitive lookups. nil
+	// if case insensitive lookups are disabled.
+	ciIndex map[string]string
+
+	// Prefix of the meta keys, eg: _. in _.code.
+	meta string
+
+	// Whether JSON language maps may have comments (see WithJSONC()).
+	jsonc bool
+
+	// Delimiters of {params} in values (see WithDelimiters()).
+	delims delims
+
+	// Separator of plural forms and select branches in values. Default is |.
+	sep string
+
+	// Descriptions of keys for translators from the _meta.* keys (see Description()).
+	descs map[string]string
+
+	// Sources (provenance) of the translations from the _src.* keys (see Source()).
+	srcs map[string]string
+
+	// Candidate translations of keys with arrays of candidates (see Candidates()).
+	cands map[string][]Candidate
+
+	// Declared param types of keys from the _types.* keys, key => param => type.
+	types map[string]map[string]string
+
+	// Declared number of plural forms of keys (see SetKeyPluralForms()).
+	keyForms map[string]int
+
+	// Name of the CLDR plural rule family of the language (see PluralRule()).
+	rule string
+
+	// Don't trim the whitespace around plural forms (see SetTrimForms()).
+	noTrim bool
+
+	// Non-fatal issues found while loading language maps (see Warnings()).
+	warnings []string
+
+	// Cache of parsed pipe separated plural values (see parsePlural()).
+	pluralCache map[string]*pluralForms
+	pluralMu    sync.RWMutex
 }
 
-var reParam = regexp.MustCompile(`(?i)\{([a-z0-9-.]+)\}`)
+var (
+	// ErrInvalidParams is returned when an odd number of params are passed
+	// to a substitution function.
+	ErrInvalidParams = errors.New("invalid arguments")
+
+	// ErrMissingKey is returned when a key is not found in the language map.
+	ErrMissingKey = errors.New("key not found")
+
+	// ErrUnresolvedParams is returned in the strict mode when {params} in
+	// a translation are left unsubstituted.
+	ErrUnresolvedParams = errors.New("unresolved params")
+)
+
+// metaPrefix is the default prefix of special meta keys such as _.code and
+// _.name. See WithMetaPrefix().
+const metaPrefix = "_."
+
+// maxParamDepth is the maximum depth to which nested {params} are recursively
+// resolved to guard against keys that reference each other.
+const maxParamDepth = 10
 
 // New returns an I18n instance from the given JSON language map bytes.
-func New(jsonB []byte) (*I18n, error) {
-	var l map[string]string
-	if err := json.Unmarshal(jsonB, &l); err != nil {
+// The map can either be flat {"a.b.c": "value"} or nested {"a": {"b": {"c": "value"}}},
+// in which case the nested keys are flattened into dotted keys.
+// Options, eg: WithFallback() and WithStrict(), customize the instance and
+// are applied before the map is loaded.
+func New(jsonB []byte, opts ...Option) (*I18n, error) {
+	i := newInstance(opts)
+	l, order, dupes, cands, err := i.parseJSON(jsonB)
+	if err != nil {
 		return nil, err
 	}
 
-	code, ok := l["_.code"]
-	if !ok {
-		return nil, errors.New("missing _.code field in language file")
+	if err := i.init(l, order, cands); err != nil {
+		return nil, err
 	}
+	i.warnDuplicates(dupes, "")
 
-	name, ok := l["_.name"]
-	if !ok {
-		return nil, errors.New("missing _.name field in language file")
+	return i, nil
+}
+
+// newFromMap returns an I18n instance from the given flat language map. order is
+// the order of the keys in the source, and if it's nil, the keys are sorted.
+func newFromMap(l map[string]string, order []string, opts []Option) (*I18n, error) {
+	i := newInstance(opts)
+	if err := i.init(l, order, nil); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// newInstance returns an empty I18n instance with the default settings
+// and the given options applied.
+func newInstance(opts []Option) *I18n {
+	i := &I18n{
+		sep:        "|",
+		meta:       metaPrefix,
+		delims:     defaultDelims,
+		missingErr: true,
 	}
+	for _, o := range opts {
+		o(i)
+	}
+
+	return i
+}
+
+// init sets the given flat language map and the candidates of its keys
+// on a new instance.
+func (i *I18n) init(l map[string]string, order []string, cands map[string][]Candidate) error {
+	code, name, err := getMeta(l, i.meta)
+	if err != nil {
+		return err
+	}
+
+	if order == nil {
+		order = sortedKeys(l)
+	}
+	descs, order := splitPrefix(l, order, i.descPrefix())
+	srcs, order := splitPrefix(l, order, i.srcPrefix())
+	decls, order := splitPrefix(l, order, i.typesPrefix())
+	types, warns := parseTypes(decls)
+
+	i.langMap = l
+	i.order = order
+	i.descs = descs
+	i.srcs = winnerSources(srcs, cands)
+	i.cands = cands
+	i.types = types
+	i.warnings = append(i.warnings, warns...)
+	if w := pluralWarning(code); w != "" {
+		i.warnings = append(i.warnings, w)
+	}
+	i.code = code
+	i.name = name
+	i.rule = ruleName(code)
+	i.printer = message.NewPrinter(language.Make(code))
+
+	return nil
+}
+
+// metaKey returns the meta key with the given name, eg: _.code for code.
+func (i *I18n) metaKey(name string) string {
+	return i.meta + name
+}
 
-	return &I18n{
-		langMap: l,
-		code:    code,
-		name:    name,
-	}, nil
+// descPrefix returns the prefix of the keys with the descriptions of the keys
+// for translators, eg: _meta.pageTitle for pageTitle.
+func (i *I18n) descPrefix() string {
+	return i.dataPrefix("meta")
+}
+
+// srcPrefix returns the prefix of the keys with the sources of the translations
+// of the keys, eg: _src.pageTitle for pageTitle.
+func (i *I18n) srcPrefix() string {
+	return i.dataPrefix("src")
+}
+
+// typesPrefix returns the prefix of the keys with the declarations of the types
+// of the params of keys, eg: _types.pageVars for pageVars.
+func (i *I18n) typesPrefix() string {
+	return i.dataPrefix("types")
+}
+
+// dataPrefix returns the prefix of the keys with the given kind of data about
+// the keys, which is the meta prefix without its trailing dot followed by the
+// name and a dot, eg: _meta. with the default meta prefix _. and @@meta. with @@.
+func (i *I18n) dataPrefix(name string) string {
+	return strings.TrimSuffix(i.meta, ".") + name + "."
 }
 
 // NewFromFile returns a I18n instance with the JSON language map read
 // from the given file.
-func NewFromFile(filepath string) (*I18n, error) {
-	b, err := ioutil.ReadFile(filepath)
+func NewFromFile(filepath string, opts ...Option) (*I18n, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	i, err := NewFromReader(f, opts...)
+	if err != nil {
+		return nil, err
+	}
+	i.path = filepath
+
+	return i, nil
+}
+
+// NewFromReader returns an I18n instance with the JSON language map read from
+// the given reader, eg: a file, a network response, or a gzip reader. The map is
+// decoded as it's streamed in, without buffering the whole document in memory,
+// except with WithJSONC().
+func NewFromReader(r io.Reader, opts ...Option) (*I18n, error) {
+	var (
+		i     = newInstance(opts)
+		l     map[string]string
+		order []string
+		dupes []string
+		cands map[string][]Candidate
+		err   error
+	)
+	if i.jsonc {
+		var b []byte
+		if b, err = ioutil.ReadAll(r); err != nil {
+			return nil, err
+		}
+		l, order, dupes, cands, err = i.parseJSON(b)
+	} else {
+		l, order, dupes, cands, err = decodeMap(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.init(l, order, cands); err != nil {
+		return nil, err
+	}
+	i.warnDuplicates(dupes, "")
+
+	return i, nil
+}
+
+// NewFromFS returns a I18n instance with the JSON language map read
+// from the given file in the given filesystem, eg: an embed.FS.
+func NewFromFS(fsys fs.FS, path string, opts ...Option) (*I18n, error) {
+	b, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := New(b, opts...)
+	if err != nil {
+		return nil, err
+	}
+	i.path = path
+	i.fsys = fsys
+
+	return i, nil
+}
+
+// NewVariant returns an I18n instance for a regional variant of the parent's
+// language, eg: en-GB for en, from the given JSON language map that only has the
+// keys that differ from the parent's, eg: "colour" and "centre". The keys that are
+// missing in the variant are resolved from the parent, which is the variant's
+// fallback (see SetFallback()), while Code() is that of the variant. The _.code of
+// the variant should be a region of the parent's base language, eg: en-GB or en_AU
+// for en or en-US.
+func NewVariant(parent *I18n, b []byte, opts ...Option) (*I18n, error) {
+	i, err := New(b, append([]Option{WithFallback(parent)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if code, pc := i.Code(), parent.Code(); !isVariantOf(code, pc) {
+		return nil, fmt.Errorf("language %s is not a regional variant of %s", code, pc)
+	}
+
+	return i, nil
+}
+
+// isVariantOf returns true if the language code is a region of the base
+// language of the parent code, eg: en-GB of en or en-US.
+func isVariantOf(code, parent string) bool {
+	base := baseCode(code)
+	return base != strings.ToLower(code) && base == baseCode(parent) && !strings.EqualFold(code, parent)
+}
+
+// getFallback returns the fallback instance.
+func (i *I18n) getFallback() *I18n {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.fallback
+}
+
+// NewFromGlob returns an I18n instance with the language maps read from all the
+// files matching the given filepath.Glob() pattern, in the lexical order of their
+// paths, merged into one, eg: for languages split into several files. Files are
+// parsed with the Parser registered for their extension (see RegisterParser()),
+// eg: YAML files with the yaml subpackage imported, and as JSON otherwise. The
+// files that have the _.code key should all have the same code. Keys in later
+// files overwrite the conflicting ones in the earlier files, and a warning with
+// each such key and the path of the file that overwrote it is recorded in
+// Warnings().
+func NewFromGlob(pattern string, opts ...Option) (*I18n, error) {
+	files, err := filepath.Glob(pattern)
 	if err != nil {
 		return nil, err
 	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %s", pattern)
+	}
+
+	var (
+		i       = newInstance(opts)
+		codeKey = i.metaKey("code")
+		out     = make(map[string]string)
+		order   []string
+		code    string
+		dupes   = make(map[string][]string)
+		over    = make(map[string][]string)
+		cands   = make(map[string][]Candidate)
+	)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			l  map[string]string
+			lo []string
+			c  map[string][]Candidate
+		)
+		if p, ok := getParser(filepath.Ext(f)); ok {
+			var m map[string]interface{}
+			if m, err = p(b); err == nil {
+				l, err = flattenMap(m)
+			}
+		} else {
+			l, lo, dupes[f], c, err = i.parseJSON(b)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		if lo == nil {
+			lo = sortedKeys(l)
+		}
+
+		if c, ok := l[codeKey]; ok {
+			if code != "" && c != code {
+				return nil, fmt.Errorf("%s: language code %s differs from %s", f, c, code)
+			}
+			code = c
+		}
+
+		for _, k := range lo {
+			if old, ok := out[k]; !ok {
+				order = append(order, k)
+			} else if k != codeKey && old != l[k] {
+				over[f] = append(over[f], k)
+			}
+			out[k] = l[k]
+
+			if cs, ok := c[k]; ok {
+				cands[k] = cs
+			} else {
+				delete(cands, k)
+			}
+		}
+	}
+	if len(cands) == 0 {
+		cands = nil
+	}
+
+	if err := i.init(out, order, cands); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		i.warnDuplicates(dupes[f], f)
+		i.warnKeys(over[f], f, "overwritten key ")
+	}
 
-	return New(b)
+	return i, nil
 }
 
 // Load loads a JSON language map into the instance overwriting
 // existing keys that conflict.
 func (i *I18n) Load(b []byte) error {
-	var l map[string]string
-	if err := json.Unmarshal(b, &l); err != nil {
+	l, order, dupes, cands, err := i.parseJSON(b)
+	if err != nil {
 		return err
 	}
 
-	for k, v := range l {
-		i.langMap[k] = v
+	if err := i.loadMap(l, order, cands); err != nil {
+		return err
 	}
-
+	i.warnDuplicates(dupes, "")
 	return nil
 }
 
-// Name returns the canonical name of the language.
-func (i *I18n) Name() string {
-	return i.name
+// LoadWith loads a JSON language map into the instance like Load(), except that
+// for the keys that already exist in the instance, resolve is called with the key,
+// and the existing and the incoming values, and the value it returns is used, eg:
+// to keep the existing values (first wins), or to never overwrite a reviewed
+// translation with a machine translated one. resolve is called without holding
+// the instance's lock and may look up the instance, eg: Source(). The _src.*
+// source of an incoming value is loaded only if resolve returns the incoming
+// value, and the existing source is retained otherwise. With a nil resolve, the
+// incoming values win as with Load().
+func (i *I18n) LoadWith(b []byte, resolve func(key, existing, incoming string) string) error {
+	l, order, dupes, cands, err := i.parseJSON(b)
+	if err != nil {
+		return err
+	}
+
+	if resolve != nil {
+		v := i.view()
+		for k, in := range l {
+			ex, ok := v.langMap[k]
+			if !ok {
+				continue
+			}
+
+			l[k] = resolve(k, ex, in)
+			if l[k] == in {
+				continue
+			}
+			if _, ok := l[i.srcPrefix()+k]; ok {
+				l[i.srcPrefix()+k] = i.Source(k)
+			}
+			if _, ok := cands[k]; ok {
+				delete(cands, k)
+				if c := i.Candidates(k); c != nil {
+					cands[k] = c
+				}
+			}
+		}
+	}
+
+	if err := i.loadMap(l, order, cands); err != nil {
+		return err
+	}
+	i.warnDuplicates(dupes, "")
+	return nil
 }
 
-// Code returns the ISO code of the language.
-func (i *I18n) Code() string {
-	return i.code
+// loadMap copies the given flat language map, and the candidates of its keys,
+// into the instance overwriting existing keys that conflict. New keys are
+// appended to the key order in the given order, or sorted if it's nil. If case
+// insensitive lookups are enabled, an error is returned, and nothing is loaded,
+// if any of the keys differ only in case from each other or the existing keys.
+func (i *I18n) loadMap(l map[string]string, order []string, cands map[string][]Candidate) error {
+	if order == nil {
+		order = sortedKeys(l)
+	}
+	descs, order := splitPrefix(l, order, i.descPrefix())
+	srcs, order := splitPrefix(l, order, i.srcPrefix())
+	decls, order := splitPrefix(l, order, i.typesPrefix())
+	types, warns := parseTypes(decls)
+	srcs = winnerSources(srcs, cands)
+
+	i.mu.Lock()
+	if dupes := caseCollisions(i.ciIndex, l); len(dupes) > 0 {
+		i.mu.Unlock()
+		return caseError(dupes)
+	}
+	i.setCandidates(cands, l)
+	for k, v := range descs {
+		if i.descs == nil {
+			i.descs = make(map[string]string)
+		}
+		i.descs[k] = v
+	}
+	for k, v := range srcs {
+		if i.srcs == nil {
+			i.srcs = make(map[string]string)
+		}
+		i.srcs[k] = v
+	}
+	if types != nil {
+		t := make(map[string]map[string]string, len(i.types)+len(types))
+		for k, v := range i.types {
+			t[k] = v
+		}
+		for k, v := range types {
+			t[k] = v
+		}
+		i.types = t
+	}
+	i.warnings = append(i.warnings, warns...)
+	for _, k := range order {
+		if _, ok := i.langMap[k]; !ok {
+			i.order = append(i.order, k)
+		}
+	}
+	m, ci := i.copyMaps()
+	for k, v := range l {
+		m[k] = i.normalize(k, v)
+		if ci != nil {
+			ci[strings.ToLower(k)] = k
+		}
+	}
+	i.langMap, i.ciIndex = m, ci
+	i.mu.Unlock()
+	i.resetPluralCache()
+
+	return nil
 }
 
-// JSON returns the languagemap as raw JSON.
-func (i *I18n) JSON() []byte {
-	b, _ := json.Marshal(i.langMap)
-	return b
+// copyMaps returns copies of the language map and the case index, if there's
+// one, to modify and swap in. It should be called with the lock held.
+func (i *I18n) copyMaps() (map[string]string, map[string]string) {
+	var ci map[string]string
+	if i.ciIndex != nil {
+		ci = copyMap(i.ciIndex)
+	}
+
+	return copyMap(i.langMap), ci
 }
 
-// T returns the translation string for the given key.
-func (i *I18n) T(key string) string {
-	s, ok := i.langMap[key]
-	if !ok {
-		return key
+// Set sets the translation for a single key in the language map. The
+// language code (_.code) can only be changed with SetCode(). As the map is
+// copy-on-write, every Set() copies it, and Load() should be used to set
+// many keys. If case insensitive lookups are enabled, an error is returned
+// for a new key that differs only in case from an existing key.
+func (i *I18n) Set(key, value string) error {
+	if key == i.metaKey("code") {
+		return fmt.Errorf("cannot change %s, use SetCode()", key)
+	}
+
+	i.mu.Lock()
+	if dupes := caseCollisions(i.ciIndex, map[string]string{key: value}); len(dupes) > 0 {
+		i.mu.Unlock()
+		return caseError(dupes)
 	}
+	if _, ok := i.langMap[key]; !ok {
+		i.order = append(i.order, key)
+	}
+	m, ci := i.copyMaps()
+	m[key] = i.normalize(key, value)
+	delete(i.cands, key)
+	if key == i.metaKey("name") {
+		i.name = value
+	}
+	if ci != nil {
+		ci[strings.ToLower(key)] = key
+	}
+	i.langMap, i.ciIndex = m, ci
+	i.mu.Unlock()
+	i.resetPluralCache()
 
-	return i.getSingular(s)
+	return nil
 }
 
-// Ts returns the translation for the given key similar to vue i18n's t()
-// and substitutes the params in the given map in the translated value.
-// In the language values, the substitutions are represented as: {key}
-// The params and values are received as a pairs of succeeding strings.
-// That is, the number of these arguments should be an even number.
-// eg: Ts("globals.message.notFound",
-//
-//	"name", "campaigns",
-//	"error", err)
-func (i *I18n) Ts(key string, params ...string) string {
-	if len(params)%2 != 0 {
-		return key + `: invalid arguments`
+// Delete deletes a single key from the language map. The mandatory
+// _.code and _.name keys cannot be deleted.
+func (i *I18n) Delete(key string) error {
+	if key == i.metaKey("code") || key == i.metaKey("name") {
+		return fmt.Errorf("cannot delete %s", key)
 	}
 
-	s, ok := i.langMap[key]
-	if !ok {
-		return key
+	i.mu.Lock()
+	if _, ok := i.langMap[key]; !ok {
+		i.mu.Unlock()
+		return nil
 	}
+	for n, k := range i.order {
+		if k == key {
+			i.order = append(i.order[:n:n], i.order[n+1:]...)
+			break
+		}
+	}
+	m, ci := i.copyMaps()
+	delete(m, key)
+	delete(i.cands, key)
+	if lk := strings.ToLower(key); ci != nil && ci[lk] == key {
+		delete(ci, lk)
 
-	s = i.getSingular(s)
-	for n := 0; n < len(params); n += 2 {
-		// If there are {params} in the param values, substitute them.
-		val := i.subAllParams(params[n+1])
-		s = strings.ReplaceAll(s, `{`+params[n]+`}`, val)
+		// Point the index to a remaining key that differs only in case, if
+		// there's one, eg: from before case insensitivity was enabled.
+		for k := range m {
+			if strings.ToLower(k) == lk {
+				ci[lk] = k
+				break
+			}
+		}
 	}
+	i.langMap, i.ciIndex = m, ci
+	i.mu.Unlock()
+	i.resetPluralCache()
 
-	return s
+	return nil
 }
 
-// Tc returns the translation for the given key similar to vue i18n's tc().
-// It expects the language string in the map to be of the form `Singular | Plural` and
-// returns `Plural` if n > 1, or `Singular` otherwise.
-func (i *I18n) Tc(key string, n int) string {
-	s, ok := i.langMap[key]
-	if !ok {
-		return key
+// Merge copies the language map of another instance of the same language into
+// the instance overwriting existing keys that conflict, like Load(). The _.code and
+// _.name meta keys of the instance are retained. An error is returned if the
+// language codes of the instances are different.
+func (i *I18n) Merge(other *I18n) error {
+	if a, b := other.Code(), i.Code(); a != b {
+		return fmt.Errorf("cannot merge language %s into %s", a, b)
 	}
 
-	// Plural.
-	if n > 1 {
-		return i.getPlural(s)
+	other.mu.RLock()
+	var (
+		l     = make(map[string]string, len(other.langMap))
+		order = make([]string, 0, len(other.order))
+	)
+	for _, k := range other.order {
+		if k == other.metaKey("code") || k == other.metaKey("name") {
+			continue
+		}
+		l[k] = other.langMap[k]
+		order = append(order, k)
 	}
+	for k, v := range other.descs {
+		l[i.descPrefix()+k] = v
+	}
+	for k, v := range other.srcs {
+		l[i.srcPrefix()+k] = v
+	}
+	var cands map[string][]Candidate
+	if other.cands != nil {
+		cands = make(map[string][]Candidate, len(other.cands))
+		for k, c := range other.cands {
+			cands[k] = c
+		}
+	}
+	for k, v := range other.types {
+		l[i.typesPrefix()+k] = formatTypes(v)
+	}
+	other.mu.RUnlock()
 
-	return i.getSingular(s)
+	return i.loadMap(l, order, cands)
 }
 
-// S returns the singular form of a string that's represented as Singular|Plural.
-func (i *I18n) S(key string) string {
-	return i.Tc(key, 1)
+// Reload re-reads the file the instance was created from with NewFromFile()
+// or NewFromFS() and atomically replaces the language map with it. Keys loaded
+// into the instance with Load() that are not in the file are discarded.
+// Translation calls that are in progress complete with the old map.
+// The language code in the file should not change.
+func (i *I18n) Reload() error {
+	if i.path == "" {
+		return errors.New("instance was not created from a file")
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+	if i.fsys != nil {
+		b, err = fs.ReadFile(i.fsys, i.path)
+	} else {
+		b, err = ioutil.ReadFile(i.path)
+	}
+	if err != nil {
+		return err
+	}
+
+	l, order, dupes, cands, err := i.parseJSON(b)
+	if err != nil {
+		return err
+	}
+
+	code, name, err := getMeta(l, i.meta)
+	if err != nil {
+		return err
+	}
+	if old := i.Code(); code != old {
+		return fmt.Errorf("language code changed from %s to %s", old, code)
+	}
+
+	descs, order := splitPrefix(l, order, i.descPrefix())
+	srcs, order := splitPrefix(l, order, i.srcPrefix())
+	decls, order := splitPrefix(l, order, i.typesPrefix())
+	types, warns := parseTypes(decls)
+	srcs = winnerSources(srcs, cands)
+
+	i.mu.Lock()
+	var ci map[string]string
+	if i.ciIndex != nil {
+		idx, dupes := buildCaseIndex(l)
+		if len(dupes) > 0 {
+			i.mu.Unlock()
+			return caseError(dupes)
+		}
+		ci = idx
+	}
+	for k, v := range l {
+		l[k] = i.normalize(k, v)
+	}
+	i.langMap = l
+	i.order = order
+	i.descs = descs
+	i.srcs = srcs
+	i.cands = cands
+	i.types = types
+	i.name = name
+	if ci != nil {
+		i.ciIndex = ci
+	}
+	i.warnings = warns
+	if w := pluralWarning(code); w != "" {
+		i.warnings = append(i.warnings, w)
+	}
+	i.mu.Unlock()
+	i.resetPluralCache()
+	i.warnDuplicates(dupes, "")
+
+	return nil
 }
 
-// P returns the Plural form of a string that's represented as Singular|Plural.
-func (i *I18n) P(key string) string {
-	return i.Tc(key, 2)
+// SetResolver sets an optional resolver function that's called to resolve keys
+// that are missing in the language map, eg: by fetching them from a database or
+// a remote service, before the fallback instance is looked up. The resolved
+// strings are processed like the ones in the language map (plurals, params etc.).
+// The resolver is responsible for caching the resolved strings, if required,
+// and it should be safe for concurrent use.
+func (i *I18n) SetResolver(fn func(key string) (string, bool)) {
+	i.mu.Lock()
+	i.resolver = fn
+	i.mu.Unlock()
 }
 
-// getSingular returns the singular term from the vuei18n pipe separated value.
-// singular term | plural term
-func (i *I18n) getSingular(s string) string {
-	if !strings.Contains(s, "|") {
-		return s
+// SetFallback sets a fallback I18n instance (eg: English) that's looked up
+// when a key is missing in the instance's language map. The fallback can have
+// its own fallback, forming a chain.
+func (i *I18n) SetFallback(fb *I18n) {
+	i.mu.Lock()
+	i.fallback = fb
+	i.mu.Unlock()
+}
+
+// LoadFromFS loads a JSON language map from the given file in the given
+// filesystem into the instance overwriting existing keys that conflict.
+func (i *I18n) LoadFromFS(fsys fs.FS, path string) error {
+	b, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return err
 	}
 
-	return strings.TrimSpace(strings.Split(s, "|")[0])
+	return i.Load(b)
 }
 
-// getSingular returns the plural term from the vuei18n pipe separated value.
-// singular term | plural term
-func (i *I18n) getPlural(s string) string {
-	if !strings.Contains(s, "|") {
-		return s
+// Description returns the description of the given key for translators, eg: "Button
+// label, keep it short", from the _meta.key key in the language map, eg: _meta.save
+// or {"_meta": {"save": "..."}} for save. The _meta.* keys are not a part of the
+// language map and are never rendered. An empty string is returned if the key
+// has no description.
+func (i *I18n) Description(key string) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.descs[key]
+}
+
+// Source returns the source (provenance) of the translation of the given key, eg:
+// "human", "machine", or the file or the service it came from, from the _src.key
+// key in the language map, eg: _src.save or {"_src": {"save": "..."}} for save, or
+// the source of the winner of the key's candidates (see Candidates()). Like the
+// _meta.* keys, the _src.* keys are not a part of the language map.
+// An empty string is returned if the key has no source.
+func (i *I18n) Source(key string) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.srcs[key]
+}
+
+// splitPrefix removes the keys with the given prefix, eg: the _meta.* description
+// keys, from the language map and the key order and returns them as a map of the
+// keys without the prefix.
+func splitPrefix(l map[string]string, order []string, prefix string) (map[string]string, []string) {
+	var out map[string]string
+	for k, v := range l {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[strings.TrimPrefix(k, prefix)] = v
+		delete(l, k)
+	}
+	if out == nil {
+		return nil, order
 	}
 
-	chunks := strings.Split(s, "|")
-	if len(chunks) == 2 {
-		return strings.TrimSpace(chunks[1])
+	keys := make([]string, 0, len(order))
+	for _, k := range order {
+		if !strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
 	}
 
-	return strings.TrimSpace(chunks[0])
+	return out, keys
 }
 
-// subAllParams recursively resolves and replaces all {params} in a string.
-func (i *I18n) subAllParams(s string) string {
-	if !strings.Contains(s, `{`) {
-		return s
+// Warnings returns the non-fatal issues found in the language maps loaded into
+// the instance, eg: keys that appear more than once in a file, in which case the
+// last value is used. Reload() clears the earlier warnings.
+func (i *I18n) Warnings() []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return append([]string(nil), i.warnings...)
+}
+
+// warnDuplicates records warnings for duplicate keys found in the given source.
+func (i *I18n) warnDuplicates(keys []string, src string) {
+	i.warnKeys(keys, src, "duplicate key ")
+}
+
+// warnKeys records a warning with the given message prefix for each of the
+// keys, prefixed with src, eg: the file, if it's not empty.
+func (i *I18n) warnKeys(keys []string, src, msg string) {
+	if len(keys) == 0 {
+		return
+	}
+	if src != "" {
+		src += ": "
 	}
 
-	parts := reParam.FindAllStringSubmatch(s, -1)
-	if len(parts) < 1 {
-		return s
+	i.mu.Lock()
+	for _, k := range keys {
+		i.warnings = append(i.warnings, src+msg+k)
+	}
+	i.mu.Unlock()
+}
+
+// getMeta returns the mandatory code and name meta fields (_.code and _.name
+// by default) with the given meta prefix from a language map.
+func getMeta(l map[string]string, prefix string) (string, string, error) {
+	code, ok := l[prefix+"code"]
+	if !ok {
+		return "", "", fmt.Errorf("missing %scode field in language file", prefix)
 	}
 
-	for _, p := range parts {
-		s = strings.ReplaceAll(s, p[0], i.T(p[1]))
+	name, ok := l[prefix+"name"]
+	if !ok {
+		return "", "", fmt.Errorf("missing %sname field in language file", prefix)
 	}
 
-	return i.subAllParams(s)
+	return code, name, nil
+}
+
+// parseMap parses a flat or nested JSON language map into a flat map of dotted
+// keys and returns it along with the keys in their source order, the keys that
+// appear more than once, and the candidates of the keys that have them.
+func parseMap(b []byte) (map[string]string, []string, []string, map[string][]Candidate, error) {
+	return decodeMap(bytes.NewReader(b))
+}
+
+// parseJSON is parseMap() that strips comments and trailing commas from the JSON
+// first if the instance accepts them (see WithJSONC()).
+func (i *I18n) parseJSON(b []byte) (map[string]string, []string, []string, map[string][]Candidate, error) {
+	if i.jsonc {
+		b = stripJSONC(b)
+	}
+
+	return parseMap(b)
+}
+
+// decodeMap decodes a flat or nested JSON language map from the reader token by
+// token, without unmarshalling the whole document, into a flat map of dotted keys
+// and returns it along with the keys in their source order, the keys that appear
+// more than once, eg: {"a.b": "x", "a": {"b": "y"}}, and the candidates of the keys
+// with arrays of candidates, if any (see Candidates()). Numbers are retained as
+// they are in the source, eg: "1.50", when they're converted to strings.
+func decodeMap(r io.Reader) (map[string]string, []string, []string, map[string][]Candidate, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	t, err := dec.Token()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, nil, nil, nil, errors.New("language map should be a JSON object")
+	}
+
+	var (
+		out          = make(map[string]string)
+		order, dupes []string
+		cands        = make(map[string][]Candidate)
+	)
+	if err := decodeObject(dec, "", out, &order, &dupes, cands); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, nil, nil, nil, errors.New("invalid data after the language map")
+	}
+	if len(cands) == 0 {
+		cands = nil
+	}
+
+	return out, order, dupes, cands, nil
+}
+
+// decodeObject decodes the keys and values of a JSON object from the decoder
+// (after its opening brace) into out with dotted keys, and appends the new keys
+// to order. Keys that repeat overwrite the earlier values and are appended to dupes.
+// The candidates of keys with arrays of candidates are added to cands, and their
+// winners' texts are the values.
+func decodeObject(dec *json.Decoder, prefix string, out map[string]string, order, dupes *[]string, cands map[string][]Candidate) error {
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		k := t.(string)
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+
+		t, err = dec.Token()
+		if err != nil {
+			return err
+		}
+
+		// A value replaces the earlier candidates of a repeated key.
+		delete(cands, k)
+
+		var val string
+		switch v := t.(type) {
+		case string:
+			val = v
+		case json.Number:
+			val = v.String()
+		case bool:
+			val = strconv.FormatBool(v)
+		case nil:
+			return fmt.Errorf("invalid value for %s: null", k)
+		case json.Delim:
+			if v == '[' {
+				c, err := decodeCandidates(dec, k)
+				if err != nil {
+					return err
+				}
+
+				cands[k] = c
+				val = winner(c).Text
+				break
+			}
+			if err := decodeObject(dec, k, out, order, dupes, cands); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, ok := out[k]; !ok {
+			*order = append(*order, k)
+		} else {
+			*dupes = append(*dupes, k)
+		}
+		out[k] = val
+	}
+
+	// Closing brace.
+	_, err := dec.Token()
+	return err
+}
+
+// flatten recursively flattens a nested map into out with dotted keys.
+// Scalar numeric and boolean values are converted to strings.
+func flatten(prefix string, m map[string]interface{}, out map[string]string) error {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+
+		switch v := v.(type) {
+		case string:
+			out[k] = v
+		case bool, int, int64, uint64:
+			out[k] = fmt.Sprintf("%v", v)
+		case float64:
+			out[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		case nil:
+			return fmt.Errorf("invalid value for %s: null", k)
+		case map[string]interface{}:
+			if err := flatten(k, v, out); err != nil {
+				return err
+			}
+		case []interface{}:
+			return fmt.Errorf("invalid value for %s: arrays are not supported", k)
+		default:
+			return fmt.Errorf("invalid value for %s: expected string, number, boolean, or object, got %T", k, v)
+		}
+	}
+
+	return nil
+}
+
+// SetMissingKeyErr sets whether TsE() returns an ErrMissingKey error for
+// keys that are missing. It's enabled by default.
+func (i *I18n) SetMissingKeyErr(on bool) {
+	i.mu.Lock()
+	i.missingErr = on
+	i.mu.Unlock()
+}
+
+// OnMissing sets an optional callback that's invoked with the key and the language
+// code whenever a translation function (T, Ts, Tc etc.) is called with a key that's
+// missing in the language map and in the fallback chain.
+func (i *I18n) OnMissing(fn func(key, code string)) {
+	i.mu.Lock()
+	i.onMissing = fn
+	i.mu.Unlock()
+}
+
+// SetMissingFormat sets an optional function that returns the string that T(),
+// Ts(), Tc() and the other translation functions return for keys that are missing,
+// instead of the bare key, eg: to wrap them in markers like ⟦key⟧ to spot
+// untranslated strings during development. Passing nil restores the default.
+func (i *I18n) SetMissingFormat(fn func(key string) string) {
+	i.mu.Lock()
+	i.missingFmt = fn
+	i.mu.Unlock()
+}
+
+// missing returns the string to return for a missing key.
+func (i *I18n) missing(key string) string {
+	i.mu.RLock()
+	fn := i.missingFmt
+	i.mu.RUnlock()
+
+	if fn == nil {
+		return key
+	}
+
+	return fn(key)
+}
+
+// SetGlobals sets params, eg: the app name or the support e-mail, that are
+// available to Ts() and all the other substitution functions without having
+// to be passed on every call. Params passed to a call override the globals
+// with the same name. Passing nil removes the globals.
+// eg: SetGlobals(map[string]interface{}{"supportEmail": "help@site.com"})
+func (i *I18n) SetGlobals(globals map[string]interface{}) {
+	var g map[string]interface{}
+	if len(globals) > 0 {
+		g = make(map[string]interface{}, len(globals))
+		for k, v := range globals {
+			g[k] = v
+		}
+	}
+
+	i.mu.Lock()
+	i.globals = g
+	i.mu.Unlock()
+}
+
+// SetStrict sets the strict mode in which TsE() returns an ErrUnresolvedParams
+// error listing the {params} in the translation that were not substituted,
+// eg: due to typos in param names or missing params.
+func (i *I18n) SetStrict(on bool) {
+	i.mu.Lock()
+	i.strict = on
+	i.mu.Unlock()
+}
+
+// SetHTMLEscape sets whether param values substituted by Ts() and the other
+// substitution functions are HTML escaped. The translation strings themselves,
+// and the translations of nested {key} references in param values, are not
+// escaped so that intentional markup in them is retained.
+func (i *I18n) SetHTMLEscape(on bool) {
+	i.mu.Lock()
+	i.htmlEscape = on
+	i.mu.Unlock()
+}
+
+// SetBidiIsolate sets whether param values substituted by Ts() and the other
+// substitution functions are wrapped in the Unicode first strong isolate (U+2068)
+// and pop directional isolate (U+2069) characters, so that mixed direction text,
+// eg: a Latin name or a number in an Arabic or Hebrew string, or vice versa, is
+// displayed in the right order regardless of the direction of the param value.
+func (i *I18n) SetBidiIsolate(on bool) {
+	i.mu.Lock()
+	i.bidiIsolate = on
+	i.mu.Unlock()
+}
+
+// SetCaseInsensitive enables or disables case insensitive key lookups, eg:
+// T("PAGETITLE") resolving pageTitle, with the exact case matches taking
+// precedence. An error listing the keys that differ only in case is returned,
+// and case insensitive lookups are not enabled, if there are any such keys.
+func (i *I18n) SetCaseInsensitive(on bool) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !on {
+		i.ciIndex = nil
+		return nil
+	}
+
+	idx, dupes := buildCaseIndex(i.langMap)
+	if len(dupes) > 0 {
+		return caseError(dupes)
+	}
+	i.ciIndex = idx
+
+	return nil
+}
+
+// SetEmptyAsMissing sets whether the keys with empty or whitespace-only values in
+// the instance's language map, eg: from partially translated files, are treated
+// as missing, in which case the resolver and the fallback chain are looked up,
+// and T() and the other translation functions return the missing key and invoke
+// the OnMissing() callback, instead of returning an empty string.
+func (i *I18n) SetEmptyAsMissing(on bool) {
+	i.mu.Lock()
+	i.emptyMissing = on
+	i.mu.Unlock()
+}
+
+// SetNormalizeWhitespace enables or disables the normalization of whitespace in
+// the values in the language map, eg: stray spaces from copy-pasting. If on, the
+// leading and trailing whitespace (including non-breaking spaces) in values are
+// trimmed. If collapse is also set, runs of whitespace within values, except
+// inside {params}, are collapsed into single spaces. The values already in the
+// map are normalized, as are the ones loaded or set subsequently. The values of
+// the meta (_.*) keys, eg: _.listSep, are retained as they are.
+func (i *I18n) SetNormalizeWhitespace(on, collapse bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.trimSpace, i.collapseSpace = on, on && collapse
+	if !on {
+		return
+	}
+	i.resetPluralCache()
+
+	m := make(map[string]string, len(i.langMap))
+	for k, v := range i.langMap {
+		m[k] = i.normalize(k, v)
+	}
+	i.langMap = m
+}
+
+// normalize normalizes the whitespace in the value of a key as per the
+// whitespace normalization settings. The values of the meta (_.*) keys and the
+// _meta.*, _src.*, and _types.* keys are not normalized, eg: a _.listSep of ", ".
+// It should be called with the lock held.
+func (i *I18n) normalize(key, s string) string {
+	if !i.trimSpace || i.isReserved(key) {
+		return s
+	}
+
+	s = strings.TrimSpace(s)
+	if !i.collapseSpace {
+		return s
+	}
+
+	var (
+		b       strings.Builder
+		inParam bool
+		inSpace bool
+	)
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '{':
+			inParam = true
+		case r == '}':
+			inParam = false
+		case !inParam && unicode.IsSpace(r):
+			if !inSpace {
+				b.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+
+		inSpace = false
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// isReserved returns true if the key is a meta (_.*) key or a _meta.*, _src.*,
+// or _types.* key.
+func (i *I18n) isReserved(key string) bool {
+	return strings.HasPrefix(key, i.meta) || strings.HasPrefix(key, i.descPrefix()) ||
+		strings.HasPrefix(key, i.srcPrefix()) || strings.HasPrefix(key, i.typesPrefix())
+}
+
+// sortedKeys returns the sorted keys of a language map.
+func sortedKeys(l map[string]string) []string {
+	out := make([]string, 0, len(l))
+	for k := range l {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// buildCaseIndex returns a lowercased key => key index of a language map
+// and the sorted list of keys that collide when lowercased. Colliding keys
+// are indexed to the first one in the sorted order.
+func buildCaseIndex(l map[string]string) (map[string]string, []string) {
+	var (
+		idx   = make(map[string]string, len(l))
+		dupes []string
+	)
+	for _, k := range sortedKeys(l) {
+		lk := strings.ToLower(k)
+		if d, ok := idx[lk]; ok {
+			dupes = append(dupes, d, k)
+			continue
+		}
+		idx[lk] = k
+	}
+	sort.Strings(dupes)
+
+	return idx, dupes
+}
+
+// caseCollisions returns the sorted list of the keys in l that differ only
+// in case from each other or from the keys in the case index idx, along with
+// the keys they collide with. It returns nil if idx is nil.
+func caseCollisions(idx map[string]string, l map[string]string) []string {
+	if idx == nil {
+		return nil
+	}
+
+	var (
+		seen  = make(map[string]string, len(l))
+		dupes = map[string]string{}
+	)
+	for k := range l {
+		lk := strings.ToLower(k)
+		if d, ok := idx[lk]; ok && d != k {
+			dupes[d], dupes[k] = d, k
+		}
+		if d, ok := seen[lk]; ok && d != k {
+			dupes[d], dupes[k] = d, k
+		}
+		seen[lk] = k
+	}
+	if len(dupes) == 0 {
+		return nil
+	}
+
+	return sortedKeys(dupes)
+}
+
+// caseError returns the error for keys that differ only in case.
+func caseError(dupes []string) error {
+	return fmt.Errorf("keys that differ only in case: %s", strings.Join(dupes, ", "))
+}
+
+// Name returns the canonical name of the language.
+func (i *I18n) Name() string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.name
+}
+
+// Code returns the ISO code of the language.
+func (i *I18n) Code() string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.code
+}
+
+// SetCode sets the ISO code of the language (_.code), eg: when generating
+// language maps programmatically. The plural rules and the number formatting
+// of the new language apply subsequently.
+func (i *I18n) SetCode(code string) {
+	i.mu.Lock()
+	i.code = code
+	i.rule = ruleName(code)
+	m, ci := i.copyMaps()
+	m[i.metaKey("code")] = code
+	i.langMap, i.ciIndex = m, ci
+	i.printer = message.NewPrinter(language.Make(code))
+	i.mu.Unlock()
+}
+
+// SetName sets the canonical name of the language (_.name).
+func (i *I18n) SetName(name string) {
+	_ = i.Set(i.metaKey("name"), name)
+}
+
+// Meta returns the value of the given meta key in the instance's language map,
+// eg: Meta("direction") or Meta("_.direction") for the _.direction key.
+func (i *I18n) Meta(key string) (string, bool) {
+	if !strings.HasPrefix(key, i.meta) {
+		key = i.meta + key
+	}
+
+	i.mu.RLock()
+	v, ok := i.langMap[key]
+	i.mu.RUnlock()
+
+	return v, ok
+}
+
+// rtlScripts is the list of right-to-left ISO 15924 scripts.
+var rtlScripts = map[string]bool{
+	"Arab": true, "Hebr": true, "Thaa": true, "Syrc": true, "Nkoo": true,
+	"Adlm": true, "Rohg": true, "Mand": true, "Samr": true,
+}
+
+// Direction returns the text direction of the language, "rtl" or "ltr", from
+// the optional _.direction meta key. If it's not set, the direction is derived
+// from the language code's (likely) script, eg: "rtl" for ar, he, fa, and ur-Arab.
+func (i *I18n) Direction() string {
+	if d, ok := i.Meta("direction"); ok {
+		if d = strings.ToLower(strings.TrimSpace(d)); d == "rtl" || d == "ltr" {
+			return d
+		}
+	}
+
+	if s, _ := language.Make(i.Code()).Script(); rtlScripts[s.String()] {
+		return "rtl"
+	}
+
+	return "ltr"
+}
+
+// locale returns the language code and the locale printer of the instance.
+func (i *I18n) locale() (string, *message.Printer) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.code, i.printer
+}
+
+// JSON returns the languagemap as raw JSON.
+func (i *I18n) JSON() []byte {
+	i.mu.RLock()
+	b, _ := json.Marshal(i.langMap)
+	i.mu.RUnlock()
+
+	return b
+}
+
+// JSONOrdered returns the language map as raw JSON like JSON(), but with the keys
+// in the order in which they were loaded from the source files, with the keys
+// added later by Load() or Set() at the end. Keys of nested maps are dotted.
+// The candidates, descriptions, sources, and param types of keys are included
+// like in the source files, after the keys, so that it can be loaded back.
+func (i *I18n) JSONOrdered() []byte {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var b bytes.Buffer
+	fn := func(k string, v interface{}) {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+
+		kb, _ := json.Marshal(k)
+		vb, _ := json.Marshal(v)
+		b.Write(kb)
+		b.WriteByte(':')
+		b.Write(vb)
+	}
+
+	b.WriteByte('{')
+	for _, k := range i.order {
+		i.exportKey(k, fn)
+	}
+	for _, k := range i.detachedKeys() {
+		i.exportKey(k, fn)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes()
+}
+
+// exportKey calls fn with the key and its value, or its candidates, followed by
+// its description, source, and param types, if any, with the _meta.*, _src.*,
+// and _types.* keys in which they're declared in language maps. The sources of
+// keys with candidates are in their candidates. It should be called with the lock held.
+func (i *I18n) exportKey(k string, fn func(k string, v interface{})) {
+	c, hasCands := i.cands[k]
+	if hasCands {
+		fn(k, c)
+	} else if v, ok := i.langMap[k]; ok {
+		fn(k, v)
+	}
+
+	if v, ok := i.descs[k]; ok {
+		fn(i.descPrefix()+k, v)
+	}
+	if v, ok := i.srcs[k]; ok && !hasCands {
+		fn(i.srcPrefix()+k, v)
+	}
+	if v, ok := i.types[k]; ok {
+		fn(i.typesPrefix()+k, formatTypes(v))
+	}
+}
+
+// export returns the language map with the candidates, descriptions, sources,
+// and param types of keys (see exportKey()).
+func (i *I18n) export() map[string]interface{} {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	out := make(map[string]interface{}, len(i.langMap))
+	fn := func(k string, v interface{}) {
+		out[k] = v
+	}
+	for k := range i.langMap {
+		i.exportKey(k, fn)
+	}
+	for _, k := range i.detachedKeys() {
+		i.exportKey(k, fn)
+	}
+
+	return out
+}
+
+// detachedKeys returns the sorted list of keys that have descriptions, sources,
+// or param types but aren't in the language map, eg: declarations for the keys
+// in the fallback. It should be called with the lock held.
+func (i *I18n) detachedKeys() []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(k string) {
+		if _, ok := i.langMap[k]; !ok && !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	for k := range i.descs {
+		add(k)
+	}
+	for k := range i.srcs {
+		add(k)
+	}
+	for k := range i.types {
+		add(k)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// Keys returns the sorted list of keys in the language map excluding
+// the meta (_.*) keys.
+func (i *I18n) Keys() []string {
+	i.mu.RLock()
+	out := make([]string, 0, len(i.langMap))
+	for k := range i.langMap {
+		if !strings.HasPrefix(k, i.meta) {
+			out = append(out, k)
+		}
+	}
+	i.mu.RUnlock()
+
+	sort.Strings(out)
+	return out
+}
+
+// Raw returns a copy of the language map including the meta keys.
+func (i *I18n) Raw() map[string]string {
+	i.mu.RLock()
+	out := copyMap(i.langMap)
+	i.mu.RUnlock()
+
+	return out
+}
+
+// MissingFrom returns the sorted list of keys that are present in the
+// reference instance but are missing in this instance's own language map.
+func (i *I18n) MissingFrom(ref *I18n) []string {
+	return diffKeys(ref, i)
+}
+
+// ExtraVs returns the sorted list of keys that are present in this
+// instance's own language map but not in the reference instance.
+func (i *I18n) ExtraVs(ref *I18n) []string {
+	return diffKeys(i, ref)
+}
+
+// StubMissing returns an indented JSON language map with the keys that are present
+// in the reference instance but missing in the instance, with the values from the
+// reference, for translators to translate in place, eg: to complete a new language
+// started from English. The keys are in the reference's order, and descriptions
+// of the keys (_meta.*) in the reference are also included. The instance's _.code
+// and _.name, and the other meta keys missing from it, are included so that the
+// file can be loaded as is, and they're flagged for translators to update with a
+// description. The stub can be merged into the instance's file after translation.
+func (i *I18n) StubMissing(ref *I18n) []byte {
+	type kv struct{ k, v string }
+
+	var (
+		missing = make(map[string]bool)
+		code    = i.metaKey("code")
+		name    = i.metaKey("name")
+		meta    = []kv{{code, i.Code()}, {name, i.Name()}}
+		keys    []kv
+	)
+	for _, k := range diffKeys(ref, i) {
+		missing[k] = true
+	}
+
+	ref.mu.RLock()
+	for _, k := range ref.order {
+		v := ref.langMap[k]
+		if strings.HasPrefix(k, ref.meta) {
+			meta = append(meta, kv{i.metaKey(strings.TrimPrefix(k, ref.meta)), v})
+			continue
+		}
+		if !missing[k] {
+			continue
+		}
+
+		keys = append(keys, kv{k, v})
+		if d, ok := ref.descs[k]; ok {
+			keys = append(keys, kv{i.descPrefix() + k, d})
+		}
+	}
+	ref.mu.RUnlock()
+
+	var (
+		b   bytes.Buffer
+		enc = json.NewEncoder(&b)
+	)
+	enc.SetEscapeHTML(false)
+	add := func(k, v string) {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		_ = enc.Encode(k)
+		b.WriteByte(':')
+		_ = enc.Encode(v)
+	}
+
+	b.WriteByte('{')
+	for n, m := range meta {
+		// The instance's code and name are always included, and the reference's
+		// other meta keys only if the instance doesn't have them.
+		if n > 1 {
+			if _, ok := i.Meta(m.k); ok || m.k == code || m.k == name {
+				continue
+			}
+		}
+
+		add(m.k, m.v)
+		add(i.descPrefix()+m.k, "Meta key, update it for the language")
+	}
+	for _, k := range keys {
+		add(k.k, k.v)
+	}
+	b.WriteByte('}')
+
+	var out bytes.Buffer
+	_ = json.Indent(&out, b.Bytes(), "", "\t")
+
+	return out.Bytes()
+}
+
+// Unused returns the sorted list of keys in the instance's own language map that
+// are not in the given list of keys used by an application, eg: collected with
+// static analysis or by logging lookups, for pruning dead translations. The meta
+// (_.*) keys are not considered.
+func (i *I18n) Unused(used []string) []string {
+	u := make(map[string]bool, len(used))
+	for _, k := range used {
+		u[k] = true
+	}
+
+	out := []string{}
+	for _, k := range i.Keys() {
+		if !u[k] {
+			out = append(out, k)
+		}
+	}
+
+	return out
+}
+
+// diffKeys returns the keys in a that are not in b.
+func diffKeys(a, b *I18n) []string {
+	out := []string{}
+	for _, k := range a.Keys() {
+		if !b.HasOwn(k) {
+			out = append(out, k)
+		}
+	}
+
+	return out
+}
+
+// Has returns true if the given key exists in the language map (or in the
+// fallback chain). Meta keys (_.*) are not considered.
+func (i *I18n) Has(key string) bool {
+	if strings.HasPrefix(key, i.meta) {
+		return false
+	}
+
+	_, _, ok := i.lookup(key)
+	return ok
+}
+
+// HasOwn returns true if the given key exists in the instance's own
+// language map, ignoring the fallback chain. Meta keys (_.*) are not considered.
+func (i *I18n) HasOwn(key string) bool {
+	if strings.HasPrefix(key, i.meta) {
+		return false
+	}
+
+	i.mu.RLock()
+	_, ok := i.langMap[key]
+	i.mu.RUnlock()
+
+	return ok
+}
+
+// JSONIndent returns the language map as indented raw JSON with the keys sorted,
+// for persisting language maps with stable diffs. Unlike JSON(), HTML characters
+// in the values are not escaped, and the candidates, descriptions, sources, and
+// param types of keys are included like in the source files.
+func (i *I18n) JSONIndent() []byte {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+
+	// Map keys are always marshalled sorted.
+	_ = enc.Encode(i.export())
+
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// JSONNested returns the language map as raw JSON with the dotted keys expanded
+// into nested objects, eg: {"a": {"b": "value"}} for "a.b". The meta keys are
+// expanded too, eg: {"_": {"code": "en"}}, as are the candidates, descriptions,
+// sources, and param types of keys like in JSONIndent(). An error is returned if
+// a key is both a value and the parent of other keys, eg: "a" and "a.b".
+func (i *I18n) JSONNested() ([]byte, error) {
+	out := make(map[string]interface{})
+	for k, v := range i.export() {
+		var (
+			parts = strings.Split(k, ".")
+			m     = out
+		)
+		for _, p := range parts[:len(parts)-1] {
+			child, ok := m[p]
+			if !ok {
+				child = make(map[string]interface{})
+				m[p] = child
+			}
+
+			cm, ok := child.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot nest %s: %s is a value", k, p)
+			}
+			m = cm
+		}
+
+		last := parts[len(parts)-1]
+		if _, ok := m[last]; ok {
+			return nil, fmt.Errorf("cannot nest %s: it has nested keys", k)
+		}
+		m[last] = v
+	}
+
+	return json.Marshal(out)
+}
+
+// T returns the translation string for the given key.
+func (i *I18n) T(key string) string {
+	s, _, ok := i.get(key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.delims.unescape(i.getSingular(s))
+}
+
+// Lookup returns the translation for the given key like T() along with whether
+// it was found, and its source: "primary" if it's in the instance's language map
+// (or its resolver), "fallback" if it's in the fallback chain, or "missing". eg:
+// for debugging the resolution of keys. The OnMissing() callback is not invoked.
+func (i *I18n) Lookup(key string) (string, bool, string) {
+	s, src, ok := i.lookup(key)
+	if !ok {
+		return i.missing(key), false, "missing"
+	}
+
+	source := "primary"
+	if src != i {
+		source = "fallback"
+	}
+
+	return i.delims.unescape(i.getSingular(s)), true, source
+}
+
+// TMap returns the translations of the given keys as a key => translation map
+// like T(), eg: for passing a set of labels to a template. Missing keys map to
+// what T() returns for them (the key, or as per SetMissingFormat()).
+func (i *I18n) TMap(keys ...string) map[string]string {
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		out[k] = i.T(k)
+	}
+
+	return out
+}
+
+// TMapFound is like TMap() but omits the keys that are missing.
+func (i *I18n) TMapFound(keys ...string) map[string]string {
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		if s, _, ok := i.get(k); ok {
+			out[k] = i.delims.unescape(i.getSingular(s))
+		}
+	}
+
+	return out
+}
+
+// TCtx returns the translation string for the given key in the given message
+// context to disambiguate identical source strings, eg: "Post" the verb and "Post"
+// the noun. Contextual translations are stored under the keys `context|key`, eg:
+// "verb|post" and "noun|post". If there's no contextual translation, the
+// translation for the bare key is returned.
+func (i *I18n) TCtx(ctx, key string) string {
+	if s, _, ok := i.lookup(ctx + "|" + key); ok {
+		return i.delims.unescape(i.getSingular(s))
+	}
+
+	return i.T(key)
+}
+
+// TDefault returns the translation string for the given key, or the given
+// default string if the key is missing.
+func (i *I18n) TDefault(key, def string) string {
+	s, _, ok := i.get(key)
+	if !ok {
+		return def
+	}
+
+	return i.delims.unescape(i.getSingular(s))
+}
+
+// Ts returns the translation for the given key similar to vue i18n's t()
+// and substitutes the params in the given map in the translated value.
+// In the language values, the substitutions are represented as: {key}
+// The params and values are received as a pairs of succeeding values.
+// That is, the number of these arguments should be an even number.
+// Non-string values are formatted with fmt's %v.
+// eg: Ts("globals.message.notFound",
+//
+//	"name", "campaigns",
+//	"error", err)
+func (i *I18n) Ts(key string, params ...interface{}) string {
+	if len(params)%2 != 0 {
+		return key + `: invalid arguments`
+	}
+
+	opt := i.subOptions()
+	s, _, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(i.getSingular(s), pairParams(params), opt)
+}
+
+// TsD is Ts() with the given param delimiters for the call instead of the
+// instance's (see WithDelimiters()), including in the nested {key} references
+// in the param values, eg: TsD("json", "<<", ">>", "id", 1) for a value that has
+// literal braces, as in `{"id": <<id>>}`. If either of the delimiters is empty,
+// the instance's delimiters are used.
+func (i *I18n) TsD(key, left, right string, params ...interface{}) string {
+	if len(params)%2 != 0 {
+		return key + `: invalid arguments`
+	}
+
+	opt := i.subOptions()
+	if left != "" && right != "" {
+		opt.delims = delims{left, right}
+	}
+
+	s, _, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(i.getSingular(s), pairParams(params), opt)
+}
+
+// TsLazy returns a fmt.Stringer whose String() returns Ts() for the key and params,
+// deferring the substitution until it's required, eg: for log messages that may be
+// dropped based on the log level. The translation is looked up when String() is
+// called, and again on every call. The params should not be modified after.
+// eg: log.Debug(i.TsLazy("cacheMiss", "key", k))
+func (i *I18n) TsLazy(key string, params ...interface{}) fmt.Stringer {
+	return lazyTs{i: i, key: key, params: params}
+}
+
+// lazyTs is a deferred Ts() call. See TsLazy().
+type lazyTs struct {
+	i      *I18n
+	key    string
+	params []interface{}
+}
+
+func (l lazyTs) String() string {
+	return l.i.Ts(l.key, l.params...)
+}
+
+// FT is like Ts() but writes the translation to w as the params are substituted
+// instead of returning a string, eg: to render many strings in a server side
+// HTML template. It returns the number of bytes written and the error, if any,
+// from writing to w. An odd number of params returns an ErrInvalidParams error.
+func (i *I18n) FT(w io.Writer, key string, params ...interface{}) (int, error) {
+	if len(params)%2 != 0 {
+		return 0, fmt.Errorf("%s: %w", key, ErrInvalidParams)
+	}
+
+	opt := i.subOptions()
+	s, _, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return io.WriteString(w, i.missing(key))
+	}
+
+	p := pairParams(params)
+	return opt.delims.writeParams(w, i.getSingular(s), func(name string) (string, bool) {
+		return i.subParam(name, p, opt, nil)
+	})
+}
+
+// Interpolate substitutes the params in the given template string exactly like
+// Ts() does in translations, including the formatting of values and the nested
+// resolution of {key} references in param values, eg: for strings assembled at
+// runtime. Unlike Ts(), pipe separated plural forms in the template are retained.
+// eg: Interpolate("{count} results for {query}", "count", 5, "query", q)
+func (i *I18n) Interpolate(tmpl string, params ...interface{}) string {
+	if len(params)%2 != 0 {
+		return tmpl + `: invalid arguments`
+	}
+
+	return i.subParams(tmpl, pairParams(params), i.subOptions())
+}
+
+// TsDefault is like Ts() but substitutes the params into the given default
+// string if the key is missing.
+func (i *I18n) TsDefault(key, def string, params ...interface{}) string {
+	if len(params)%2 != 0 {
+		return key + `: invalid arguments`
+	}
+
+	opt := i.subOptions()
+	s, _, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.subParams(def, pairParams(params), opt)
+	}
+
+	return i.subParams(i.getSingular(s), pairParams(params), opt)
+}
+
+// Tsp is like Ts() but substitutes positional params, {0}, {1} ..., with the
+// arguments at the corresponding indices.
+// eg: "{0} of {1}", Tsp("pageOf", 1, 10) = "1 of 10"
+func (i *I18n) Tsp(key string, args ...interface{}) string {
+	opt := i.subOptions()
+	s, _, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(i.getSingular(s), posParams(args), opt)
+}
+
+// TsE is like Ts() but returns an ErrInvalidParams error for an odd number of
+// params, an ErrMissingKey error for missing keys (see SetMissingKeyErr()), and
+// in the strict mode, an ErrUnresolvedParams error for {params} that were not
+// substituted (see SetStrict()).
+//
+// The types of the params can be declared in the language map with the _types.*
+// keys, eg: "_types.pageVars": "name: string, count: number" for pageVars, and an
+// ErrParamType error is returned if the value of a declared param passed has a
+// different type, eg: a string for a number that would otherwise be silently
+// rendered unformatted. The types are string, number, int, bool, time (time.Time),
+// and money (Money()). Number params can also be Percent() values.
+func (i *I18n) TsE(key string, params ...interface{}) (string, error) {
+	if len(params)%2 != 0 {
+		return key, fmt.Errorf("%s: %w", key, ErrInvalidParams)
+	}
+
+	opt := i.subOptions()
+	s, src, ok := i.getIn(&opt.view, key)
+	if !ok {
+		i.mu.RLock()
+		missingErr := i.missingErr
+		i.mu.RUnlock()
+
+		if missingErr {
+			return i.missing(key), fmt.Errorf("%s: %w", key, ErrMissingKey)
+		}
+		return i.missing(key), nil
+	}
+
+	if err := i.checkParamTypes(key, src, params); err != nil {
+		out, _ := i.subParamsUnresolved(i.getSingular(s), pairParams(params), opt)
+		return out, err
+	}
+
+	out, unresolved := i.subParamsUnresolved(i.getSingular(s), pairParams(params), opt)
+
+	i.mu.RLock()
+	strict := i.strict
+	i.mu.RUnlock()
+
+	if strict && len(unresolved) > 0 {
+		return out, fmt.Errorf("%s: %w: %s", key, ErrUnresolvedParams, strings.Join(unresolved, ", "))
+	}
+
+	return out, nil
+}
+
+// Tsm is like Ts() but takes the params to substitute as a map.
+// eg: Tsm("globals.message.notFound", map[string]interface{}{"name": "campaigns"})
+func (i *I18n) Tsm(key string, params map[string]interface{}) string {
+	opt := i.subOptions()
+	s, _, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(i.getSingular(s), mapParams(params), opt)
+}
+
+// TSelect returns the translation for the given key selecting one of the labelled
+// pipe separated branches in the language string with the given selector, and
+// substitutes the params in it like Ts(). The "other" branch is used if there's no
+// branch for the selector, and the first branch if there's no "other" branch either.
+// eg: "male: He liked it | female: She liked it | other: They liked it"
+func (i *I18n) TSelect(key, selector string, params ...interface{}) string {
+	if len(params)%2 != 0 {
+		return key + `: invalid arguments`
+	}
+
+	opt := i.subOptions()
+	s, _, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(getSelectForm(s, i.pluralSep(), selector), pairParams(params), opt)
+}
+
+// Tc returns the translation for the given key similar to vue i18n's tc().
+// It expects the language string in the map to be of the form `Singular | Plural` and
+// returns `Singular` if n is 1 (or -1), or `Plural` otherwise.
+//
+// Language strings with three or more forms, eg: `one | few | many` are selected
+// using the CLDR plural rules of the language's code, or a custom plural function
+// registered for the code with RegisterPluralFunc(). A string with one more form
+// than the language's plural categories has a leading zero form that's used
+// when n is 0, eg: `No pages | Single page | Many pages` in English.
+//
+// Leading forms prefixed with a number match n exactly and take precedence over
+// the plural rules, eg: `0: No pages | 10: Ten pages | Single page | Many pages`,
+// if they're followed by at least two regular forms.
+//
+// The {n} and {count} params in the selected form are substituted with n,
+// eg: `{count} item | {count} items`, and the globals (see SetGlobals()) with
+// their values. Other params are left as-is.
+func (i *I18n) Tc(key string, n int) string {
+	opt := i.subOptions()
+	s, src, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(src.getPluralForm(key, s, n), countParams(n, nil), opt)
+}
+
+// TcKey is Tc() for plurals that are separate keys suffixed with the CLDR plural
+// category of the count in the instance's language instead of pipe separated
+// forms, eg: cart.one and cart.other in English, and cart.one, cart.few, and
+// cart.many in Russian. The suffixes are .zero, .one, .two, .few, .many, and
+// .other. For 0, the .zero key is looked up first in all languages, like the
+// leading zero form in Tc(). If there's no key for the category, the value of
+// the key without a suffix is used like Tc(), eg: cart: "{n} item|{n} items".
+// The {n} and {count} params in the value are substituted with n, and the
+// globals with their values, like Tc(). A plural function registered for the
+// language with RegisterPluralFunc() selects the category like it selects the
+// form in Tc(), with its index in the language's categories.
+func (i *I18n) TcKey(key string, n int) string {
+	opt := i.subOptions()
+	if n == 0 {
+		if s, _, ok := i.lookupIn(&opt.view, key+".zero"); ok {
+			return i.subParams(s, countParams(n, nil), opt)
+		}
+	}
+
+	var (
+		code = i.Code()
+		cats = getPluralRule(code).categories
+		cat  = cats[pluralIndex(code, n, len(cats))]
+	)
+	if s, _, ok := i.lookupIn(&opt.view, key+"."+cat); ok {
+		return i.subParams(s, countParams(n, nil), opt)
+	}
+
+	s, src, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(src.getPluralForm(key, s, n), countParams(n, nil), opt)
+}
+
+// Tcs is like Tc() but also substitutes the given params in the selected plural
+// form like Ts(). n is automatically available as the {n} and {count} params,
+// unless they are explicitly passed. If SetNumberFormat() is on, the substituted
+// count is formatted as per the language, eg: "1,234 pages", while the plural
+// form is still selected with the raw count.
+// eg: "{n} page | {n} pages", Tcs("pages", 5) = "5 pages"
+func (i *I18n) Tcs(key string, n int, params ...interface{}) string {
+	if len(params)%2 != 0 {
+		return key + `: invalid arguments`
+	}
+
+	opt := i.subOptions()
+	s, src, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(src.getPluralForm(key, s, n), countParams(n, pairParams(params)), opt)
+}
+
+// Tcf is like Tc() but selects the plural form for a fractional count, eg:
+// "1.5 hours". Integral counts, eg: 1.0, select the same form as Tc(), including
+// the exact match forms. For other counts, the form is selected as per the CLDR
+// rules for fractions of the language. eg: in English, all fractions use the
+// plural form, and in French, the singular form is also used for fractions below
+// 2, eg: "1,5 heure". In languages where fractions belong to the CLDR "other"
+// category that's not one of the forms, eg: Russian and Polish, the "few" form,
+// which has the same text, is used. Custom plural functions registered with
+// RegisterPluralFunc() only apply to the integral counts.
+//
+// The {n} and {count} params in the selected form are substituted with n.
+// eg: "{n} hour | {n} hours", Tcf("hours", 1.5) = "1.5 hours"
+func (i *I18n) Tcf(key string, n float64) string {
+	opt := i.subOptions()
+	s, src, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.subParams(src.getPluralFormFloat(key, s, n), countParams(n, nil), opt)
+}
+
+// Forms returns all the (trimmed) pipe separated forms of the translation for the
+// given key, eg: ["Single page", "Many pages"] for "Single page|Many pages", or
+// a single form for strings that are not plurals. nil is returned if the key
+// is missing.
+func (i *I18n) Forms(key string) []string {
+	s, _, ok := i.lookup(key)
+	if !ok {
+		return nil
+	}
+
+	sep, trim := i.formOptions()
+	out := strings.Split(s, sep)
+	for n, f := range out {
+		out[n] = trimForm(f, trim)
+	}
+
+	return out
+}
+
+// PlInline returns the singular or the plural string for n as per the plural
+// rules of the language, like Tc() does for `Singular | Plural` strings in the map.
+func (i *I18n) PlInline(n int, singular, plural string) string {
+	return i.PlForms(n, singular, plural)
+}
+
+// PlForms returns one of the given plural forms for n as per the plural rules of
+// the language, like Tc() does for pipe separated strings with multiple forms in
+// the map, eg: PlForms(n, "one", "few", "many") in Russian.
+func (i *I18n) PlForms(n int, forms ...string) string {
+	if len(forms) == 0 {
+		return ""
+	}
+
+	return forms[pluralIndex(i.Code(), n, len(forms))]
+}
+
+// S returns the singular form of a string that's represented as Singular|Plural.
+func (i *I18n) S(key string) string {
+	return i.form(key, 1)
+}
+
+// P returns the Plural form of a string that's represented as Singular|Plural.
+func (i *I18n) P(key string) string {
+	return i.form(key, 2)
+}
+
+// form returns the plural form for n of the given key without substituting
+// the count params.
+func (i *I18n) form(key string, n int) string {
+	s, src, ok := i.get(key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	return i.delims.unescape(src.getPluralForm(key, s, n))
+}
+
+// get is lookup() for the translation functions that also invokes the
+// OnMissing() callback when the key is missing.
+func (i *I18n) get(key string) (string, *I18n, bool) {
+	return i.getIn(nil, key)
+}
+
+// getIn is get() with the instance's pinned maps (see lookupIn()).
+func (i *I18n) getIn(v *mapView, key string) (string, *I18n, bool) {
+	s, src, ok := i.lookupIn(v, key)
+	if !ok {
+		i.mu.RLock()
+		fn := i.onMissing
+		i.mu.RUnlock()
+
+		if fn != nil {
+			fn(key, i.Code())
+		}
+	}
+
+	return s, src, ok
+}
+
+// lookup returns the raw language string for the given key from the instance's
+// language map, or if it's missing, from its resolver or the fallback chain. The instance in
+// which the key was found is also returned.
+//
+// All lookups go through here, and the read lock is only held for the duration
+// of the map access so that recursive resolution of nested {params} (which calls
+// T()) never tries to re-acquire a held lock.
+func (i *I18n) lookup(key string) (string, *I18n, bool) {
+	return i.lookupIn(nil, key)
+}
+
+// mapView is the language map and the case index of an instance pinned for the
+// duration of a translation call. As the maps are copy-on-write, a view remains
+// consistent even if the instance's map is replaced, eg: by Reload().
+type mapView struct {
+	langMap, ciIndex map[string]string
+}
+
+// view returns the instance's current maps.
+func (i *I18n) view() mapView {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return mapView{langMap: i.langMap, ciIndex: i.ciIndex}
+}
+
+// get returns the value of the key from the view.
+func (v *mapView) get(key string) (string, bool) {
+	s, ok := v.langMap[key]
+	if !ok && v.ciIndex != nil {
+		if k, has := v.ciIndex[strings.ToLower(key)]; has {
+			s, ok = v.langMap[k]
+		}
+	}
+
+	return s, ok
+}
+
+// lookupIn is lookup() that looks up the key in the given view of the instance's
+// maps, if it's not nil, instead of its current maps. The fallback instances'
+// current maps are always used.
+func (i *I18n) lookupIn(v *mapView, key string) (string, *I18n, bool) {
+	var seen []*I18n
+	for l := i; l != nil; {
+		// Fallbacks that reference each other shouldn't loop forever.
+		for _, v := range seen {
+			if v == l {
+				return "", nil, false
+			}
+		}
+		seen = append(seen, l)
+
+		l.mu.RLock()
+		lv := mapView{langMap: l.langMap, ciIndex: l.ciIndex}
+		fb, res, empty := l.fallback, l.resolver, l.emptyMissing
+		l.mu.RUnlock()
+
+		if l == i && v != nil {
+			lv = *v
+		}
+		s, ok := lv.get(key)
+		if ok && empty && strings.TrimSpace(s) == "" {
+			ok = false
+		}
+
+		if ok {
+			return s, l, true
+		}
+		if res != nil {
+			if s, ok := res(key); ok {
+				return s, l, true
+			}
+		}
+		l = fb
+	}
+
+	return "", nil, false
+}
+
+// getPluralForm returns the plural form for n from the pipe separated value of
+// the key based on the plural rules of the instance's language (see pluralIndex()).
+func (i *I18n) getPluralForm(key, s string, n int) string {
+	if !strings.Contains(s, i.pluralSep()) {
+		return s
+	}
+
+	p := i.parsePlural(s)
+	for _, e := range p.exact {
+		if e.n == n {
+			return e.form
+		}
+	}
+
+	code, forms := i.keyPluralForms(key, p)
+	if len(forms) == 1 {
+		return forms[0]
+	}
+
+	return forms[pluralIndex(code, n, len(forms))]
+}
+
+// getPluralFormFloat is getPluralForm() for a float n (see pluralIndexFloat()).
+func (i *I18n) getPluralFormFloat(key, s string, n float64) string {
+	if n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32 {
+		return i.getPluralForm(key, s, int(n))
+	}
+	if !strings.Contains(s, i.pluralSep()) {
+		return s
+	}
+
+	code, forms := i.keyPluralForms(key, i.parsePlural(s))
+	if len(forms) == 1 {
+		return forms[0]
+	}
+
+	return forms[pluralIndexFloat(code, n, len(forms))]
+}
+
+// keyPluralForms returns the language code and the plural forms of the parsed
+// value of the key from which a form is selected, which are the first n forms if
+// the key is declared to have n forms with SetKeyPluralForms().
+func (i *I18n) keyPluralForms(key string, p *pluralForms) (string, []string) {
+	i.mu.RLock()
+	code, n := i.code, i.keyForms[key]
+	i.mu.RUnlock()
+
+	if n > 0 && n < len(p.forms) {
+		return code, p.forms[:n]
+	}
+
+	return code, p.forms
+}
+
+// SetKeyPluralForms declares that the value of the key has n plural forms, eg:
+// for brand specific wording that intentionally doesn't follow the language's
+// plural rules, overriding the language's default. The form for a count is then
+// selected from the first n forms of the value as if it had n forms, eg: with 2,
+// as Singular|Plural, and with 1, the first form is used for all the counts, and
+// Validate() reports the key only if it doesn't have n forms. An n of 0 or less
+// removes the declaration.
+func (i *I18n) SetKeyPluralForms(key string, n int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if n <= 0 {
+		delete(i.keyForms, key)
+		return
+	}
+
+	if i.keyForms == nil {
+		i.keyForms = make(map[string]int)
+	}
+	i.keyForms[key] = n
+}
+
+// pluralForms is a parsed pipe separated plural value.
+type pluralForms struct {
+	// The separator the value was parsed with and whether the forms are trimmed.
+	sep  string
+	trim bool
+
+	// Leading exact match forms, eg: "0: No pages" in "0: No pages|Page|Pages".
+	exact []exactForm
+
+	// The (trimmed) plural forms.
+	forms []string
+}
+
+type exactForm struct {
+	n    int
+	form string
+}
+
+// maxPluralCache is the maximum number of parsed plural values cached per
+// instance, as values from resolvers are also cached.
+const maxPluralCache = 10000
+
+// parsePlural returns the parsed forms of a pipe separated plural value,
+// cached by the value.
+func (i *I18n) parsePlural(s string) *pluralForms {
+	sep, trim := i.formOptions()
+
+	i.pluralMu.RLock()
+	p, ok := i.pluralCache[s]
+	i.pluralMu.RUnlock()
+	if ok && p.sep == sep && p.trim == trim {
+		return p
+	}
+
+	p = parsePlural(s, sep, trim)
+
+	i.pluralMu.Lock()
+	if i.pluralCache == nil || len(i.pluralCache) >= maxPluralCache {
+		i.pluralCache = make(map[string]*pluralForms)
+	}
+	i.pluralCache[s] = p
+	i.pluralMu.Unlock()
+
+	return p
+}
+
+// SetPluralSeparator sets the separator of the plural forms, and the TSelect()
+// branches, in the values, eg: "||" for languages where "|" appears in the text,
+// as in "Name | Description". An empty separator restores the default "|".
+func (i *I18n) SetPluralSeparator(sep string) {
+	if sep == "" {
+		sep = "|"
+	}
+
+	i.mu.Lock()
+	i.sep = sep
+	i.mu.Unlock()
+	i.resetPluralCache()
+}
+
+// SetTrimForms sets whether the whitespace around the plural forms and the exact
+// match forms in values is trimmed, which it is by default so that the forms can
+// be spaced for readability, eg: "Page | Pages". With false, the forms are used
+// as-is for values that intentionally start or end with spaces, eg: for
+// concatenation as in " and | , ".
+func (i *I18n) SetTrimForms(trim bool) {
+	i.mu.Lock()
+	i.noTrim = !trim
+	i.mu.Unlock()
+	i.resetPluralCache()
+}
+
+// formOptions returns the separator of the plural forms and whether they're trimmed.
+func (i *I18n) formOptions() (string, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.sep, !i.noTrim
+}
+
+// trimForm returns the plural form s, trimmed if trim is true.
+func trimForm(s string, trim bool) string {
+	if trim {
+		return strings.TrimSpace(s)
+	}
+
+	return s
+}
+
+// pluralSep returns the separator of plural forms in values.
+func (i *I18n) pluralSep() string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.sep
+}
+
+// resetPluralCache clears the parsed plural value cache, eg: when the language
+// map changes and the cached values may be stale.
+func (i *I18n) resetPluralCache() {
+	i.pluralMu.Lock()
+	i.pluralCache = nil
+	i.pluralMu.Unlock()
+}
+
+// parsePlural parses a plural value with forms separated by sep. Leading forms
+// prefixed with a number are exact match forms as long as at least two regular
+// forms remain after them, so that text that starts with a number and a colon
+// in a Singular|Plural value, eg: "10:30 meeting|10:30 meetings", is not an
+// exact form. The forms are trimmed if trim is true.
+func parsePlural(s, sep string, trim bool) *pluralForms {
+	var (
+		p      = &pluralForms{sep: sep, trim: trim}
+		chunks = strings.Split(s, sep)
+		n      = 0
+	)
+	for ; n < len(chunks)-2; n++ {
+		label, val, ok := strings.Cut(chunks[n], ":")
+		if !ok {
+			break
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(label))
+		if err != nil {
+			break
+		}
+
+		p.exact = append(p.exact, exactForm{n: num, form: trimForm(val, trim)})
+	}
+
+	p.forms = make([]string, 0, len(chunks)-n)
+	for _, c := range chunks[n:] {
+		p.forms = append(p.forms, trimForm(c, trim))
+	}
+
+	return p
+}
+
+// getSelectForm returns the branch for the selector from a value with labelled
+// branches separated by sep, eg: male: He | female: She | other: They.
+func getSelectForm(s, sep, selector string) string {
+	var (
+		first, other       string
+		hasFirst, hasOther bool
+	)
+	for _, c := range strings.Split(s, sep) {
+		label, val, ok := strings.Cut(c, ":")
+		if !ok {
+			continue
+		}
+
+		label, val = strings.TrimSpace(label), strings.TrimSpace(val)
+		if label == selector {
+			return val
+		}
+		if label == "other" {
+			other, hasOther = val, true
+		}
+		if !hasFirst {
+			first, hasFirst = val, true
+		}
+	}
+
+	if hasOther {
+		return other
+	}
+	if hasFirst {
+		return first
+	}
+
+	// No labelled branches.
+	return s
+}
+
+// getSingular returns the singular term from the vuei18n pipe separated value,
+// skipping exact match forms, if any.
+// singular term | plural term
+func (i *I18n) getSingular(s string) string {
+	if !strings.Contains(s, i.pluralSep()) {
+		return s
+	}
+
+	return i.parsePlural(s).forms[0]
+}
+
+// paramFunc returns the value of the named param, if it exists.
+type paramFunc func(name string) (interface{}, bool)
+
+// pairParams returns a paramFunc for a list of succeeding name, value pairs.
+func pairParams(params []interface{}) paramFunc {
+	return func(name string) (interface{}, bool) {
+		for n := 0; n+1 < len(params); n += 2 {
+			if toString(params[n]) == name {
+				return params[n+1], true
+			}
+		}
+
+		return nil, false
+	}
+}
+
+// posParams returns a paramFunc for positional {0}, {1} ... params.
+func posParams(args []interface{}) paramFunc {
+	return func(name string) (interface{}, bool) {
+		n, err := strconv.Atoi(name)
+		if err != nil || n < 0 || n >= len(args) {
+			return nil, false
+		}
+
+		return args[n], true
+	}
+}
+
+// mapParams returns a paramFunc for a map of params.
+func mapParams(params map[string]interface{}) paramFunc {
+	return func(name string) (interface{}, bool) {
+		v, ok := params[name]
+		return v, ok
+	}
+}
+
+// countParams returns a paramFunc that returns n for the {n} and {count}
+// params unless they're in the given params (if any).
+func countParams(n interface{}, params paramFunc) paramFunc {
+	return func(name string) (interface{}, bool) {
+		if params != nil {
+			if v, ok := params(name); ok {
+				return v, true
+			}
+		}
+		if name == "n" || name == "count" {
+			return n, true
+		}
+
+		return nil, false
+	}
+}
+
+// subParams substitutes the given params in the string.
+func (i *I18n) subParams(s string, params paramFunc, opt subOpt) string {
+	out, _ := i.subParamsUnresolved(s, params, opt)
+	return out
+}
+
+// subParamsUnresolved substitutes the given params in the string and also
+// returns the names of the {params} in it that were not substituted.
+func (i *I18n) subParamsUnresolved(s string, params paramFunc, opt subOpt) (string, []string) {
+	var unresolved []string
+	out := opt.delims.replaceParams(s, func(name string) (string, bool) {
+		return i.subParam(name, params, opt, &unresolved)
+	})
+
+	return out, unresolved
+}
+
+// subOpt has the settings for substituting params and the pinned maps
+// in which nested {key} references are resolved.
+type subOpt struct {
+	globals              map[string]interface{}
+	esc, numFormat, bidi bool
+	delims               delims
+	view                 mapView
+}
+
+// subOptions returns the instance's settings for substituting params
+// and its current maps.
+func (i *I18n) subOptions() subOpt {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return subOpt{
+		globals:   i.globals,
+		esc:       i.htmlEscape,
+		numFormat: i.numFormat,
+		bidi:      i.bidiIsolate,
+		delims:    i.delims,
+		view:      mapView{langMap: i.langMap, ciIndex: i.ciIndex},
+	}
+}
+
+// subParam returns the formatted value of the named param to substitute. Params
+// override the globals (see SetGlobals()) with the same name. If the param
+// doesn't exist, its name is appended to unresolved, if it's not nil.
+func (i *I18n) subParam(name string, params paramFunc, opt subOpt, unresolved *[]string) (string, bool) {
+	v, ok := params(name)
+	if !ok {
+		v, ok = opt.globals[name]
+	}
+	if !ok {
+		if unresolved != nil && isParamName(name) {
+			*unresolved = append(*unresolved, name)
+		}
+		return "", false
+	}
+
+	val := i.formatValue(v, opt.numFormat, &opt.view)
+	if opt.esc {
+		val = html.EscapeString(val)
+	}
+
+	// If there are {params} in the param values, substitute them.
+	val = i.subAllParams(val, 0, nil, &opt.view, opt.delims)
+	if opt.bidi {
+		val = bidiIsolate(val)
+	}
+
+	return val, true
+}
+
+// bidiIsolate wraps s in the first strong isolate and pop directional
+// isolate characters.
+func bidiIsolate(s string) string {
+	return "\u2068" + s + "\u2069"
+}
+
+// toString returns the string representation of a param value. json.Numbers
+// are rendered as they are, eg: "12.340", retaining their precision.
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// subAllParams recursively resolves and replaces all {params} in a string
+// with their translations. A param with a count suffix, eg: {summary:5}, is
+// resolved to the plural form for the count like Tc(). The optional params
+// are substituted before resolving keys. Keys are looked up in the given
+// view of the instance's maps, if it's not nil (see lookupIn()), and the params
+// have the given delimiters. Beyond maxParamDepth levels of recursion, the
+// remaining {params} are returned as-is.
+func (i *I18n) subAllParams(s string, depth int, params paramFunc, v *mapView, d delims) string {
+	if depth >= maxParamDepth {
+		return s
+	}
+
+	i.mu.RLock()
+	numFormat := i.numFormat
+	i.mu.RUnlock()
+
+	return d.replaceParams(s, func(key string) (string, bool) {
+		if params != nil {
+			if pv, ok := params(key); ok {
+				return i.formatValue(pv, numFormat, v), true
+			}
+		}
+
+		// Plural reference with a count.
+		if name, count, ok := strings.Cut(key, ":"); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || strings.HasPrefix(count, "+") || !isParamName(name) {
+				return "", false
+			}
+
+			val, src, ok := i.lookupIn(v, name)
+			if !ok {
+				return name, true
+			}
+
+			return i.subAllParams(src.getPluralForm(name, val, n), depth+1, countParams(n, nil), v, d), true
+		}
+
+		if !isParamName(key) {
+			return "", false
+		}
+
+		val, _, ok := i.lookupIn(v, key)
+		if !ok {
+			return key, true
+		}
+
+		return i.subAllParams(i.getSingular(val), depth+1, nil, v, d), true
+	})
+}
+
+// isParamName returns true if s is a valid {param} name that can be resolved to
+// another key, ie: it only has ASCII letters, digits, hyphens, and dots.
+func isParamName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for n := 0; n < len(s); n++ {
+		c := s[n]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// delims are the left and right delimiters of {params} in values.
+type delims struct {
+	l, r string
+}
+
+var defaultDelims = delims{"{", "}"}
+
+// replaceParams scans s once and replaces every {param} for which fn returns
+// true with the returned value. Replaced values are not scanned again.
+// Escaped delimiters, {{ and }}, are rendered as literal { and } and are never
+// treated as params.
+func (d delims) replaceParams(s string, fn func(name string) (string, bool)) string {
+	if !strings.Contains(s, d.l) && !strings.Contains(s, d.r) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for n := 0; n < len(s); {
+		out, adv := d.next(s[n:], fn)
+		b.WriteString(out)
+		n += adv
+	}
+
+	return b.String()
+}
+
+// writeParams is like replaceParams() but writes the text and the replaced
+// values to w as it scans s, and returns the number of bytes written.
+func (d delims) writeParams(w io.Writer, s string, fn func(name string) (string, bool)) (int, error) {
+	var total int
+	for n := 0; n < len(s); {
+		out, adv := d.next(s[n:], fn)
+		c, err := io.WriteString(w, out)
+		total += c
+		if err != nil {
+			return total, err
+		}
+		n += adv
+	}
+
+	return total, nil
+}
+
+// next returns the next segment of s to output, which is either the text up to
+// the next delimiter, an escaped delimiter, or a replaced {param}, and the number
+// of bytes of s that it consumes.
+func (d delims) next(s string, fn func(name string) (string, bool)) (string, int) {
+	// Text up to the next possible delimiter.
+	k := 0
+	for k < len(s) && s[k] != d.l[0] && s[k] != d.r[0] {
+		k++
+	}
+	if k > 0 {
+		return s[:k], k
+	}
+
+	switch {
+	// Escaped {{ or }}.
+	case strings.HasPrefix(s, d.l) && strings.HasPrefix(s[len(d.l):], d.l):
+		return d.l, len(d.l) * 2
+	case strings.HasPrefix(s, d.r) && strings.HasPrefix(s[len(d.r):], d.r):
+		return d.r, len(d.r) * 2
+
+	case fn != nil && strings.HasPrefix(s, d.l):
+		name := s[len(d.l):]
+		if end := strings.Index(name, d.r); end >= 0 && !strings.Contains(name[:end], d.l) {
+			if v, ok := fn(name[:end]); ok {
+				return v, len(d.l) + end + len(d.r)
+			}
+		}
+	}
+
+	return s[:1], 1
+}
+
+// unescape renders the escaped delimiters, {{ and }}, in s as literal { and }.
+func (d delims) unescape(s string) string {
+	return d.replaceParams(s, nil)
 }
@@ -1,8 +1,16 @@
 package i18n
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 )
 
 func TestTestXxx(t *testing.T) {
@@ -29,7 +37,7 @@ func TestTestXxx(t *testing.T) {
 	assert(t, i.T("page"), "Single page")
 	assert(t, i.S("page"), "Single page")
 	assert(t, i.P("page"), "Many pages")
-	assert(t, i.Tc("page", 0), "Single page")
+	assert(t, i.Tc("page", 0), "Many pages")
 	assert(t, i.Tc("page", 1), "Single page")
 	assert(t, i.Tc("page", 2), "Many pages")
 	assert(t, i.S("foo"), "Foo")
@@ -46,3 +54,1464 @@ func assert(t *testing.T, a, v interface{}) {
 		t.Fatalf("expected '%v', got '%v'", a, v)
 	}
 }
+
+func TestPlurals(t *testing.T) {
+	j := `
+{
+	"_.code": "ru",
+	"_.name": "Russian",
+
+	"page": "одна страница|несколько страниц|много страниц",
+	"item": "предмет|предметы"
+}
+`
+
+	i, err := New([]byte(j))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.Tc("page", 1), "одна страница")
+	assert(t, i.Tc("page", 21), "одна страница")
+	assert(t, i.Tc("page", 2), "несколько страниц")
+	assert(t, i.Tc("page", 24), "несколько страниц")
+	assert(t, i.Tc("page", 5), "много страниц")
+	assert(t, i.Tc("page", 11), "много страниц")
+	assert(t, i.Tc("page", 12), "много страниц")
+	assert(t, i.Tc("page", 0), "много страниц")
+
+	// Two forms retain the Singular|Plural behaviour.
+	assert(t, i.Tc("item", 1), "предмет")
+	assert(t, i.Tc("item", 5), "предметы")
+	assert(t, i.Tc("item", 21), "предметы")
+
+	assert(t, pluralCategory("ar", 0), 0)
+	assert(t, pluralCategory("ar", 2), 2)
+	assert(t, pluralCategory("ar", 105), 3)
+	assert(t, pluralCategory("ar", 111), 4)
+	assert(t, pluralCategory("ar", 100), 5)
+	assert(t, pluralCategory("pl", 22), 1)
+	assert(t, pluralCategory("pl", 25), 2)
+	assert(t, pluralCategory("en-US", 1), 0)
+	assert(t, pluralCategory("en", 3), 1)
+}
+
+func TestFallback(t *testing.T) {
+	en, err := New([]byte(`{"_.code": "en", "_.name": "English", "page": "Page|Pages", "foo": "Foo", "bar": "Bar {foo}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	de, err := New([]byte(`{"_.code": "de", "_.name": "German", "foo": "Fu"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ru, err := New([]byte(`{"_.code": "ru", "_.name": "Russian", "item": "предмет|предмета|предметов"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	de.SetFallback(ru)
+	ru.SetFallback(en)
+
+	assert(t, de.T("foo"), "Fu")
+	assert(t, de.T("page"), "Page")
+	assert(t, de.S("page"), "Page")
+	assert(t, de.P("page"), "Pages")
+	assert(t, de.Tc("page", 2), "Pages")
+	assert(t, de.Tc("item", 5), "предметов")
+	assert(t, de.Ts("bar"), "Bar {foo}")
+	assert(t, de.Ts("bar", "foo", "{foo}"), "Bar Fu")
+	assert(t, de.T("missing"), "missing")
+
+	// Circular references.
+	en.SetFallback(de)
+	assert(t, de.T("missing"), "missing")
+	assert(t, en.T("foo"), "Foo")
+	assert(t, en.T("item"), "предмет")
+}
+
+func TestConcurrentLoad(t *testing.T) {
+	i, err := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar {foo}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := i.Load([]byte(`{"foo": "Foo"}`)); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = i.T("foo")
+			_ = i.Ts("bar", "foo", "{foo}")
+			_ = i.Tc("foo", 2)
+			_ = i.JSON()
+		}()
+	}
+	wg.Wait()
+
+	assert(t, i.T("foo"), "Foo")
+}
+
+func TestRecursiveParams(t *testing.T) {
+	i, err := New([]byte(`{"_.code": "en", "_.name": "English", "msg": "Value: {val}", "a": "{b}", "b": "{a}", "c": "{c}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.Ts("msg", "val", "{a}"), "Value: {a}")
+	assert(t, i.Ts("msg", "val", "{b}"), "Value: {b}")
+	assert(t, i.Ts("msg", "val", "{c}"), "Value: {c}")
+}
+
+func TestNested(t *testing.T) {
+	i, err := New([]byte(`{"_": {"code": "en", "name": "English"}, "globals": {"message": {"notFound": "{name} not found"}}, "foo": "Foo"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := New([]byte(`{"_.code": "en", "_.name": "English", "globals.message.notFound": "{name} not found", "foo": "Foo"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.Code(), "en")
+	assert(t, i.Ts("globals.message.notFound", "name", "Page"), "Page not found")
+	assert(t, string(i.JSON()), string(f.JSON()))
+
+	if err := i.Load([]byte(`{"globals": {"message": {"ok": "OK"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("globals.message.ok"), "OK")
+
+	if _, err := New([]byte(`{"_.code": "en", "_.name": "English", "a": {"b": []}}`)); err == nil {
+		t.Fatal("expected error for empty array value")
+	}
+	if _, err := New([]byte(`{"_.code": "en", "_.name": "English", "a": {"b": null}}`)); err == nil {
+		t.Fatal("expected error for null value")
+	}
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lang/en.json":   {Data: []byte(`{"_.code": "en", "_.name": "English", "foo": "Foo"}`)},
+		"lang/en-2.json": {Data: []byte(`{"bar": "Bar"}`)},
+	}
+
+	i, err := NewFromFS(fsys, "lang/en.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.LoadFromFS(fsys, "lang/en-2.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.T("foo"), "Foo")
+	assert(t, i.T("bar"), "Bar")
+
+	if _, err := NewFromFS(fsys, "lang/xx.json"); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestHas(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "foo", "bar": "Bar"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "foo": "foo"}`))
+	de.SetFallback(en)
+
+	assert(t, de.Has("foo"), true)
+	assert(t, de.Has("bar"), true)
+	assert(t, de.Has("baz"), false)
+	assert(t, de.Has("_.code"), false)
+	assert(t, de.HasOwn("foo"), true)
+	assert(t, de.HasOwn("bar"), false)
+	assert(t, de.HasOwn("_.name"), false)
+}
+
+func TestDefault(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo|Foos", "bar": "Bar {name}"}`))
+
+	assert(t, i.TDefault("foo", "Default"), "Foo")
+	assert(t, i.TDefault("baz", "Default"), "Default")
+	assert(t, i.TsDefault("bar", "Default {name}", "name", "x"), "Bar x")
+	assert(t, i.TsDefault("baz", "Default {name}", "name", "x"), "Default x")
+	assert(t, i.TsDefault("baz", "Default {n}", "n", 5), "Default 5")
+	assert(t, i.Ts("bar", "name", 1.5), "Bar 1.5")
+	assert(t, i.Ts("bar", "name", errors.New("error")), "Bar error")
+}
+
+func TestEscapedBraces(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "set": "The set is {{a, b, c}}", "code": "Use {{{name}}} for {name}", "a": "A"}`))
+
+	assert(t, i.T("set"), "The set is {a, b, c}")
+	assert(t, i.Ts("set", "a", "x"), "The set is {a, b, c}")
+	assert(t, i.Ts("code", "name", "x"), "Use {x} for x")
+	assert(t, i.Ts("code", "name", "{{a}} {a}"), "Use {{a} A} for {a} A")
+}
+
+func TestTsm(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pageVars": "The page is named {name} and has {count} items", "foo": "Foo"}`))
+
+	assert(t, i.Tsm("pageVars", map[string]interface{}{"name": "Foo", "count": 1234}), "The page is named Foo and has 1234 items")
+	assert(t, i.Tsm("pageVars", map[string]interface{}{"name": "{foo}"}), "The page is named Foo and has {count} items")
+	assert(t, i.Tsm("pageVars", nil), "The page is named {name} and has {count} items")
+	assert(t, i.Tsm("missing", nil), "missing")
+}
+
+func TestTsE(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo {name}"}`))
+
+	s, err := i.TsE("foo", "name", "x")
+	assert(t, err, nil)
+	assert(t, s, "Foo x")
+
+	_, err = i.TsE("foo", "name")
+	assert(t, errors.Is(err, ErrInvalidParams), true)
+
+	s, err = i.TsE("bar")
+	assert(t, errors.Is(err, ErrMissingKey), true)
+	assert(t, s, "bar")
+
+	i.SetMissingKeyErr(false)
+	s, err = i.TsE("bar")
+	assert(t, err, nil)
+	assert(t, s, "bar")
+}
+
+func TestKeys(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar"}`))
+
+	assert(t, i.Keys(), []string{"bar", "foo"})
+
+	m := i.Raw()
+	assert(t, len(m), 4)
+	assert(t, m["_.code"], "en")
+
+	m["foo"] = "Changed"
+	assert(t, i.T("foo"), "Foo")
+}
+
+func TestMissingFrom(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar", "baz": "Baz"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "foo": "Fu", "extra": "Extra"}`))
+
+	assert(t, de.MissingFrom(en), []string{"bar", "baz"})
+	assert(t, de.ExtraVs(en), []string{"extra"})
+	assert(t, en.MissingFrom(en), []string{})
+}
+
+func TestTcs(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pages": "{n} page | {count} pages in {name}", "none": "No pages"}`))
+
+	assert(t, i.Tcs("pages", 1), "1 page")
+	assert(t, i.Tcs("pages", 5), "5 pages in {name}")
+	assert(t, i.Tcs("pages", 5, "name", "Foo"), "5 pages in Foo")
+	assert(t, i.Tcs("pages", 5, "count", "five"), "five pages in {name}")
+	assert(t, i.Tcs("none", 5), "No pages")
+	assert(t, i.Tcs("pages", 5, "name"), "pages: invalid arguments")
+	assert(t, i.Tcs("missing", 5), "missing")
+}
+
+func TestHTMLEscape(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "msg": "<b>Hello</b> {name}", "bold": "<b>bold</b>"}`))
+
+	assert(t, i.Ts("msg", "name", "<i>x</i> & y"), "<b>Hello</b> <i>x</i> & y")
+
+	i.SetHTMLEscape(true)
+	assert(t, i.Ts("msg", "name", "<i>x</i> & y"), "<b>Hello</b> &lt;i&gt;x&lt;/i&gt; &amp; y")
+	assert(t, i.Ts("msg", "name", "{bold}"), "<b>Hello</b> <b>bold</b>")
+	assert(t, i.T("msg"), "<b>Hello</b> {name}")
+}
+
+func TestTcKey(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "cart.zero": "Empty cart", "cart.one": "{n} item", "cart.other": "{count} items", "page": "{n} page|{n} pages", "box.one": "A box"}`))
+	assert(t, en.TcKey("cart", 0), "Empty cart")
+	assert(t, en.TcKey("cart", 1), "1 item")
+	assert(t, en.TcKey("cart", 3), "3 items")
+	assert(t, en.TcKey("page", 3), "3 pages")
+	assert(t, en.TcKey("box", 1), "A box")
+	assert(t, en.TcKey("box", 2), "box")
+
+	ru, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian", "cart.one": "{n} товар", "cart.few": "{n} товара", "cart.many": "{n} товаров"}`))
+	ru.SetFallback(en)
+	assert(t, ru.TcKey("cart", 21), "21 товар")
+	assert(t, ru.TcKey("cart", 3), "3 товара")
+	assert(t, ru.TcKey("cart", 5), "5 товаров")
+	assert(t, ru.TcKey("cart", 0), "Empty cart")
+
+	// Registered plural functions select the category.
+	RegisterPluralFunc("en", func(n int) int {
+		if n%10 == 1 {
+			return 0
+		}
+		return 1
+	})
+	defer RegisterPluralFunc("en", nil)
+	assert(t, en.TcKey("cart", 21), "21 item")
+	assert(t, en.TcKey("cart", 1), "1 item")
+	assert(t, en.TcKey("cart", 2), "2 items")
+	assert(t, en.Tc("page", 21), "21 page")
+}
+
+func TestTsD(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "json": "{\"id\": <<id>>, \"name\": \"{name}\"}", "ref": "{n} <<what>>", "items": "item|items"}`))
+
+	assert(t, i.TsD("json", "<<", ">>", "id", 1), `{"id": 1, "name": "{name}"}`)
+	assert(t, i.Ts("json", "id", 1), `{"id": <<id>>, "name": "{name}"}`)
+	assert(t, i.TsD("ref", "<<", ">>", "what", "<<items:2>>"), "{n} items")
+	assert(t, i.Ts("ref", "what", "<<items:2>>"), "{n} <<what>>")
+	assert(t, i.TsD("json", "", "", "name", "x"), `{"id": <<id>>, "name": "x"}`)
+	assert(t, i.TsD("json", "<<", ">>", "id"), "json: invalid arguments")
+	assert(t, i.TsD("missing", "<<", ">>"), "missing")
+}
+
+func TestNewVariant(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "color": "Color", "center": "Center", "page": "Page|Pages"}`))
+	gb, err := NewVariant(en, []byte(`{"_.code": "en-GB", "_.name": "English (UK)", "color": "Colour"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, gb.Code(), "en-GB")
+	assert(t, gb.T("color"), "Colour")
+	assert(t, gb.T("center"), "Center")
+	assert(t, gb.Tc("page", 2), "Pages")
+	assert(t, gb.Keys(), []string{"color"})
+
+	_, err = NewVariant(en, []byte(`{"_.code": "de-DE", "_.name": "German"}`))
+	assert(t, err, "language de-DE is not a regional variant of en")
+	_, err = NewVariant(en, []byte(`{"_.code": "en", "_.name": "English"}`))
+	assert(t, err, "language en is not a regional variant of en")
+	_, err = NewVariant(gb, []byte(`{"_.code": "en_AU", "_.name": "English (AU)"}`))
+	assert(t, err, nil)
+}
+
+func TestEmptyAsMissing(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "save": "Save", "cancel": "Cancel", "page": "Page|Pages"}`))
+	fr, _ := New([]byte(`{"_.code": "fr", "_.name": "French", "save": "", "cancel": "  ", "page": "", "only": ""}`))
+	fr.SetFallback(en)
+
+	assert(t, fr.T("save"), "")
+	assert(t, fr.T("cancel"), "  ")
+
+	var missing []string
+	fr.OnMissing(func(key, code string) {
+		missing = append(missing, key)
+	})
+	fr.SetEmptyAsMissing(true)
+	assert(t, fr.T("save"), "Save")
+	assert(t, fr.T("cancel"), "Cancel")
+	assert(t, fr.Tc("page", 2), "Pages")
+	assert(t, fr.T("only"), "only")
+	assert(t, fr.Has("only"), false)
+	assert(t, missing, []string{"only"})
+
+	fr.SetEmptyAsMissing(false)
+	assert(t, fr.T("save"), "")
+}
+
+func TestLoadWith(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "hello": "Hello", "bye": "Bye"}`))
+
+	firstWins := func(key, existing, incoming string) string { return existing }
+	assert(t, i.LoadWith([]byte(`{"hello": "Hi", "new": "New"}`), firstWins), nil)
+	assert(t, i.T("hello"), "Hello")
+	assert(t, i.T("new"), "New")
+
+	var calls []string
+	err := i.LoadWith([]byte(`{"hello": "Hey", "bye": "See you"}`), func(key, existing, incoming string) string {
+		calls = append(calls, key+": "+existing+" => "+incoming)
+		if key == "bye" {
+			return incoming
+		}
+		return i.T("new") + " " + existing
+	})
+	assert(t, err, nil)
+	assert(t, len(calls), 2)
+	assert(t, i.T("hello"), "New Hello")
+	assert(t, i.T("bye"), "See you")
+
+	assert(t, i.LoadWith([]byte(`{"hello": "Hi"}`), nil), nil)
+	assert(t, i.T("hello"), "Hi")
+	assert(t, i.LoadWith([]byte(`{"hello": }`), firstWins) != nil, true)
+}
+
+func TestBidiIsolate(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "he", "_.name": "Hebrew", "hello": "שלום {name}!", "items": "פריט אחד|{count} פריטים", "icu": "{name} שלום"}`))
+	assert(t, i.Ts("hello", "name", "John"), "שלום John!")
+
+	i.SetBidiIsolate(true)
+	assert(t, i.Ts("hello", "name", "John"), "שלום \u2068John\u2069!")
+	assert(t, i.Tc("items", 5), "\u20685\u2069 פריטים")
+	assert(t, i.TICU("icu", map[string]interface{}{"name": "John"}), "\u2068John\u2069 שלום")
+	assert(t, i.T("hello"), "שלום {name}!")
+
+	i.SetBidiIsolate(false)
+	assert(t, i.Ts("hello", "name", "John"), "שלום John!")
+}
+
+func TestRegisterPluralFunc(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "xx-YY", "_.name": "Custom", "page": "a|b|c|d", "item": "one|other"}`))
+
+	assert(t, i.Tc("page", 3), "b")
+
+	RegisterPluralFunc("xx", func(n int) int {
+		return n
+	})
+	defer RegisterPluralFunc("xx", nil)
+
+	assert(t, i.Tc("page", 0), "a")
+	assert(t, i.Tc("page", 3), "d")
+	assert(t, i.Tc("page", 10), "d")
+	assert(t, i.Tc("page", -1), "a")
+	assert(t, i.Tc("item", 0), "one")
+	assert(t, i.Tc("item", 1), "other")
+
+	RegisterPluralFunc("xx", nil)
+	assert(t, i.Tc("item", 0), "other")
+	assert(t, i.Tc("page", 3), "b")
+}
+
+func TestZeroPlurals(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "page": "Single page|Many pages", "pages": "No pages | Single page | Many pages"}`))
+
+	assert(t, i.Tc("page", 0), "Many pages")
+	assert(t, i.Tc("page", 1), "Single page")
+	assert(t, i.Tc("page", 2), "Many pages")
+	assert(t, i.Tc("page", -1), "Single page")
+	assert(t, i.Tc("page", -3), "Many pages")
+
+	assert(t, i.Tc("pages", 0), "No pages")
+	assert(t, i.Tc("pages", 1), "Single page")
+	assert(t, i.Tc("pages", 2), "Many pages")
+	assert(t, i.Tc("pages", -3), "Many pages")
+
+	fr, _ := New([]byte(`{"_.code": "fr", "_.name": "French", "page": "page|pages"}`))
+	assert(t, fr.Tc("page", 0), "page")
+	assert(t, fr.Tc("page", 2), "pages")
+
+	ru, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian", "page": "нет страниц|страница|страницы|страниц"}`))
+	assert(t, ru.Tc("page", 0), "нет страниц")
+	assert(t, ru.Tc("page", 1), "страница")
+	assert(t, ru.Tc("page", 3), "страницы")
+	assert(t, ru.Tc("page", 10), "страниц")
+}
+
+func TestReload(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "en.json")
+	if err := os.WriteFile(fpath, []byte(`{"_.code": "en", "_.name": "English", "foo": "Foo"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := NewFromFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("foo"), "Foo")
+
+	if err := os.WriteFile(fpath, []byte(`{"_.code": "en", "_.name": "English (US)", "foo": "Foo 2"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("foo"), "Foo 2")
+	assert(t, i.Name(), "English (US)")
+
+	if err := os.WriteFile(fpath, []byte(`{"_.code": "de", "_.name": "German"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Reload(); err == nil {
+		t.Fatal("expected error for changed language code")
+	}
+	assert(t, i.T("foo"), "Foo 2")
+
+	n, _ := New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	if err := n.Reload(); err == nil {
+		t.Fatal("expected error reloading an instance not created from a file")
+	}
+}
+
+func TestStrict(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "msg": "{name} has {count} items {{literal}}"}`))
+
+	s, err := i.TsE("msg", "name", "Foo")
+	assert(t, err, nil)
+	assert(t, s, "Foo has {count} items {literal}")
+
+	i.SetStrict(true)
+	s, err = i.TsE("msg", "nmae", "Foo")
+	assert(t, errors.Is(err, ErrUnresolvedParams), true)
+	assert(t, err.Error(), "msg: unresolved params: name, count")
+	assert(t, s, "{name} has {count} items {literal}")
+
+	_, err = i.TsE("msg", "name", "Foo", "count", 1)
+	assert(t, err, nil)
+}
+
+func TestOnMissing(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "foo": "Fu"}`))
+	de.SetFallback(en)
+
+	var missing []string
+	de.OnMissing(func(key, code string) {
+		missing = append(missing, code+":"+key)
+	})
+
+	de.T("foo")
+	de.T("bar")
+	de.T("a")
+	de.Ts("b", "x", "y")
+	de.Tc("c", 2)
+	de.Has("d")
+
+	assert(t, missing, []string{"de:a", "de:b", "de:c"})
+}
+
+func TestMerge(t *testing.T) {
+	auth, _ := New([]byte(`{"_.code": "en", "_.name": "English", "auth.login": "Login", "common": "Auth"}`))
+	billing, _ := New([]byte(`{"_.code": "en", "_.name": "English (Billing)", "billing.pay": "Pay", "common": "Billing"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German"}`))
+
+	if err := auth.Merge(billing); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, auth.T("auth.login"), "Login")
+	assert(t, auth.T("billing.pay"), "Pay")
+	assert(t, auth.T("common"), "Billing")
+	assert(t, auth.Name(), "English")
+
+	if err := auth.Merge(de); err == nil {
+		t.Fatal("expected error merging a different language")
+	}
+}
+
+func TestTCtx(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "de", "_.name": "German", "verb|post": "Veröffentlichen", "noun|post": "Beitrag", "post": "Post", "save": "Speichern"}`))
+
+	assert(t, i.TCtx("verb", "post"), "Veröffentlichen")
+	assert(t, i.TCtx("noun", "post"), "Beitrag")
+	assert(t, i.TCtx("other", "post"), "Post")
+	assert(t, i.TCtx("verb", "save"), "Speichern")
+	assert(t, i.TCtx("verb", "missing"), "missing")
+}
+
+func TestTSelect(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English",
+		"liked": "male: He liked {item} | female: She liked {item} | other: They liked {item}",
+		"noOther": "male: He | female: She",
+		"plain": "Liked"}`))
+
+	assert(t, i.TSelect("liked", "male", "item", "it"), "He liked it")
+	assert(t, i.TSelect("liked", "female", "item", "it"), "She liked it")
+	assert(t, i.TSelect("liked", "other", "item", "it"), "They liked it")
+	assert(t, i.TSelect("liked", "unknown"), "They liked {item}")
+	assert(t, i.TSelect("noOther", "unknown"), "He")
+	assert(t, i.TSelect("plain", "male"), "Liked")
+	assert(t, i.TSelect("liked", "male", "item"), "liked: invalid arguments")
+	assert(t, i.TSelect("missing", "male"), "missing")
+}
+
+func TestJSONNested(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "globals.message.notFound": "Not found", "globals.title": "Title", "foo": "Foo"}`))
+
+	b, err := i.JSONNested()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, string(b), `{"_":{"code":"en","name":"English"},"foo":"Foo","globals":{"message":{"notFound":"Not found"},"title":"Title"}}`)
+
+	n, err := New(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, string(n.JSON()), string(i.JSON()))
+
+	c, _ := New([]byte(`{"_.code": "en", "_.name": "English", "a": "A", "a.b": "B"}`))
+	if _, err := c.JSONNested(); err == nil {
+		t.Fatal("expected error for conflicting keys")
+	}
+}
+
+func TestPlInline(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	ru, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian"}`))
+
+	assert(t, en.PlInline(1, "unit", "units"), "unit")
+	assert(t, en.PlInline(0, "unit", "units"), "units")
+	assert(t, en.PlInline(5, "unit", "units"), "units")
+	assert(t, en.PlForms(0, "no units", "unit", "units"), "no units")
+	assert(t, en.PlForms(3, "units"), "units")
+	assert(t, en.PlForms(3), "")
+
+	assert(t, ru.PlForms(1, "штука", "штуки", "штук"), "штука")
+	assert(t, ru.PlForms(3, "штука", "штуки", "штук"), "штуки")
+	assert(t, ru.PlForms(7, "штука", "штуки", "штук"), "штук")
+}
+
+func TestTcCount(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "items": "{count} item | {n} items in {name}"}`))
+
+	assert(t, i.Tc("items", 1), "1 item")
+	assert(t, i.Tc("items", 5), "5 items in {name}")
+	assert(t, i.S("items"), "{count} item")
+	assert(t, i.P("items"), "{n} items in {name}")
+}
+
+func TestJSONIndent(t *testing.T) {
+	i, _ := New([]byte(`{"_.name": "English", "_.code": "en", "foo": "<b>Foo</b>", "bar": "Bar"}`))
+
+	assert(t, string(i.JSONIndent()), "{\n\t\"_.code\": \"en\",\n\t\"_.name\": \"English\",\n\t\"bar\": \"Bar\",\n\t\"foo\": \"<b>Foo</b>\"\n}")
+}
+
+func TestSetDelete(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo"}`))
+
+	assert(t, i.Set("foo", "Foo 2"), nil)
+	assert(t, i.Set("bar", "Bar"), nil)
+	assert(t, i.Set("_.name", "English (US)"), nil)
+	assert(t, i.T("foo"), "Foo 2")
+	assert(t, i.T("bar"), "Bar")
+	assert(t, i.Name(), "English (US)")
+
+	assert(t, i.Delete("foo"), nil)
+	assert(t, i.Has("foo"), false)
+
+	if err := i.Set("_.code", "de"); err == nil {
+		t.Fatal("expected error setting _.code")
+	}
+	if err := i.Delete("_.code"); err == nil {
+		t.Fatal("expected error deleting _.code")
+	}
+	if err := i.Delete("_.name"); err == nil {
+		t.Fatal("expected error deleting _.name")
+	}
+	assert(t, i.Code(), "en")
+}
+
+func TestResolver(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "foo": "Fu"}`))
+	de.SetFallback(en)
+
+	remote := map[string]string{"items": "{count} Artikel|{count} Artikel", "hello": "Hallo {name}", "bar": "Bar (de)"}
+	de.SetResolver(func(key string) (string, bool) {
+		s, ok := remote[key]
+		return s, ok
+	})
+
+	assert(t, de.T("foo"), "Fu")
+	assert(t, de.T("bar"), "Bar (de)")
+	assert(t, de.Tc("items", 3), "3 Artikel")
+	assert(t, de.Ts("hello", "name", "Welt"), "Hallo Welt")
+	assert(t, de.T("missing"), "missing")
+}
+
+func TestNestedPlurals(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "msg": "You have {summary}", "summary": "{count} message|{count} messages", "page": "page|pages"}`))
+
+	assert(t, i.Ts("msg", "summary", "{summary:5}"), "You have 5 messages")
+	assert(t, i.Ts("msg", "summary", "{summary:1}"), "You have 1 message")
+	assert(t, i.Ts("msg", "summary", "{page:2}"), "You have pages")
+	assert(t, i.Ts("msg", "summary", "{summary}"), "You have count message")
+	assert(t, i.Ts("msg", "summary", "{missing:2}"), "You have missing")
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pageTitle": "Page title", "Foo": "Foo"}`))
+
+	assert(t, i.T("PAGETITLE"), "PAGETITLE")
+
+	assert(t, i.SetCaseInsensitive(true), nil)
+	assert(t, i.T("PAGETITLE"), "Page title")
+	assert(t, i.T("pagetitle"), "Page title")
+	assert(t, i.T("foo"), "Foo")
+
+	_ = i.Set("NewKey", "New")
+	assert(t, i.T("newkey"), "New")
+	_ = i.Delete("NewKey")
+	assert(t, i.T("newkey"), "newkey")
+
+	_ = i.Load([]byte(`{"loadedKey": "Loaded"}`))
+	assert(t, i.T("LOADEDKEY"), "Loaded")
+
+	assert(t, i.SetCaseInsensitive(false), nil)
+	assert(t, i.T("PAGETITLE"), "PAGETITLE")
+
+	c, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pageTitle": "a", "pagetitle": "b", "x": "x"}`))
+	err := c.SetCaseInsensitive(true)
+	assert(t, err, "keys that differ only in case: pageTitle, pagetitle")
+	assert(t, c.T("X"), "X")
+
+	// Keys that differ only in case from the existing keys are rejected.
+	ci, _ := New([]byte(`{"_.code": "en", "_.name": "English", "Title": "Title"}`))
+	assert(t, ci.SetCaseInsensitive(true), nil)
+	assert(t, ci.Set("title", "title"), "keys that differ only in case: Title, title")
+	assert(t, ci.Keys(), []string{"Title"})
+	assert(t, ci.Load([]byte(`{"TITLE": "x", "new": "New"}`)), "keys that differ only in case: TITLE, Title")
+	assert(t, ci.Keys(), []string{"Title"})
+	assert(t, ci.T("TITLE"), "Title")
+
+	// Deleting a colliding key points the index to the remaining one.
+	o := ci.WithOverlay(map[string]string{"Name": "Name", "name": "name"})
+	assert(t, o.T("NAME"), "Name")
+	assert(t, o.Delete("Name"), nil)
+	assert(t, o.T("NAME"), "name")
+	assert(t, o.Delete("name"), nil)
+	assert(t, o.T("NAME"), "NAME")
+
+	fpath := filepath.Join(t.TempDir(), "en.json")
+	if err := os.WriteFile(fpath, []byte(`{"_.code": "en", "_.name": "English", "foo": "Foo"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, _ := NewFromFile(fpath)
+	assert(t, r.SetCaseInsensitive(true), nil)
+	if err := os.WriteFile(fpath, []byte(`{"_.code": "en", "_.name": "English", "foo": "Foo 2", "FOO": "Foo 3"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, r.Reload(), "keys that differ only in case: FOO, foo")
+	assert(t, r.T("Foo"), "Foo")
+}
+
+func TestNormalizeWhitespace(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "title": "  Page title \n", "msg": "Hello   {first  name}, \t welcome", "page": " Page |  Many   pages "}`))
+
+	assert(t, i.T("title"), "  Page title \n")
+
+	i.SetNormalizeWhitespace(true, false)
+	assert(t, i.T("title"), "Page title")
+	assert(t, i.T("msg"), "Hello   {first  name}, \t welcome")
+
+	i.SetNormalizeWhitespace(true, true)
+	assert(t, i.T("title"), "Page title")
+	assert(t, i.Ts("msg", "first  name", "Foo"), "Hello Foo, welcome")
+	assert(t, i.Tc("page", 2), "Many pages")
+
+	_ = i.Load([]byte(`{"loaded": "  Loaded  value "}`))
+	_ = i.Set("set", " Set ")
+	assert(t, i.T("loaded"), "Loaded value")
+	assert(t, i.T("set"), "Set")
+
+	i.SetNormalizeWhitespace(false, false)
+	_ = i.Set("set", " Set ")
+	assert(t, i.T("set"), " Set ")
+
+	// Meta values are not normalized.
+	fr, _ := New([]byte(`{"_.code": "fr", "_.name": "Français", "_.listSep": ", ", "_.listAnd": "et", "list": " {items} "}`))
+	fr.SetNormalizeWhitespace(true, true)
+	assert(t, fr.Ts("list", "items", []string{"a", "b", "c"}), "a, b et c")
+	_ = fr.Load([]byte(`{"_.listSep": " ; "}`))
+	assert(t, fr.Ts("list", "items", []string{"a", "b", "c"}), "a ; b et c")
+}
+
+func TestForms(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian", "page": "Single page | Many pages", "item": "a|b|c", "foo": "Foo"}`))
+
+	assert(t, i.Forms("page"), []string{"Single page", "Many pages"})
+	assert(t, i.Forms("item"), []string{"a", "b", "c"})
+	assert(t, i.Forms("foo"), []string{"Foo"})
+	assert(t, i.Forms("missing"), []string(nil))
+}
+
+func TestSetTrimForms(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "and": " and | , ", "page": "0: No pages | Page | Pages", "place": "{n}st | {n}nd | {n}rd | {n}th "}`))
+	assert(t, i.Tc("and", 1)+"|", "and|")
+	assert(t, i.Tc("page", 0), "No pages")
+
+	i.SetTrimForms(false)
+	assert(t, i.Tc("and", 1)+"|", " and |")
+	assert(t, i.Tc("and", 2)+"|", " , |")
+	assert(t, i.Tc("page", 0), " No pages ")
+	assert(t, i.Tc("page", 2), " Pages")
+	assert(t, i.Tco("place", 4), " 4th ")
+	assert(t, i.Forms("and"), []string{" and ", " , "})
+	assert(t, i.Clone().Tc("and", 1)+"|", " and |")
+
+	i.SetTrimForms(true)
+	assert(t, i.Tc("and", 1)+"|", "and|")
+}
+
+func TestPluralForms(t *testing.T) {
+	for code, n := range map[string]int{"en": 2, "fr": 2, "ru": 3, "pl": 3, "ar": 6, "ja": 1, "sl": 4, "xx": 2} {
+		i, _ := New([]byte(`{"_.code": "` + code + `", "_.name": "Lang"}`))
+		assert(t, i.PluralForms(), n)
+	}
+}
+
+func TestPluralRule(t *testing.T) {
+	for code, r := range map[string]string{"en": "one/other", "pt-BR": "one/other", "pt-PT": "one/other", "hi": "one/other", "ru": "one/few/many", "ar": "zero/one/two/few/many/other", "ja": "other"} {
+		i, _ := New([]byte(`{"_.code": "` + code + `", "_.name": "Lang"}`))
+		assert(t, i.PluralRule(), r)
+		assert(t, i.Warnings(), []string(nil))
+	}
+
+	i, _ := New([]byte(`{"_.code": "xx", "_.name": "Lang"}`))
+	assert(t, i.PluralRule(), "one/other")
+	assert(t, i.Warnings(), []string{"unknown plural rules for xx, using one/other"})
+
+	RegisterPluralFunc("xx", func(n int) int { return 0 })
+	defer RegisterPluralFunc("xx", nil)
+	assert(t, i.PluralRule(), "custom")
+
+	i.SetCode("ru")
+	assert(t, i.PluralRule(), "one/few/many")
+}
+
+func TestTsp(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pageOf": "{0} of {1}, {0} again, {2} {name}"}`))
+
+	assert(t, i.Tsp("pageOf", 1, "10"), "1 of 10, 1 again, {2} {name}")
+	assert(t, i.Tsp("pageOf"), "{0} of {1}, {0} again, {2} {name}")
+	assert(t, i.Tsp("missing", 1), "missing")
+}
+
+func TestJSONOrdered(t *testing.T) {
+	i, _ := New([]byte(`{"_.name": "English", "_.code": "en", "zoo": "Zoo", "a": {"y": "Y", "b": "B"}, "foo": "Foo"}`))
+	assert(t, string(i.JSONOrdered()), `{"_.name":"English","_.code":"en","zoo":"Zoo","a.y":"Y","a.b":"B","foo":"Foo"}`)
+
+	_ = i.Load([]byte(`{"foo": "Foo 2", "new": "New", "bar": "Bar"}`))
+	_ = i.Set("baz", "Baz")
+	_ = i.Delete("zoo")
+	assert(t, string(i.JSONOrdered()), `{"_.name":"English","_.code":"en","a.y":"Y","a.b":"B","foo":"Foo 2","new":"New","bar":"Bar","baz":"Baz"}`)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	i, err := New([]byte(`{"_.code": "en", "_.name": "English",
+		"save": [{"text": "Save", "source": "human"}, "Store"], "_meta.save": "Button label",
+		"greet": "Hi {name}", "_src.greet": "deepl", "_types.greet": "name: string",
+		"_meta.other": "Key in the fallback"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, b []byte) {
+		n, err := New(b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		assert(t, n.T("save"), "Save")
+		assert(t, n.Candidates("save"), []Candidate{{"Save", "human", true}, {"Store", "machine", false}})
+		assert(t, n.Source("save"), "human")
+		assert(t, n.Description("save"), "Button label")
+		assert(t, n.Description("other"), "Key in the fallback")
+		assert(t, n.Source("greet"), "deepl")
+		_, err = n.TsE("greet", "name", 1)
+		assert(t, errors.Is(err, ErrParamType), true)
+		assert(t, n.Keys(), []string{"greet", "save"})
+		assert(t, string(n.JSONIndent()), string(i.JSONIndent()))
+	}
+
+	check("JSONOrdered", i.JSONOrdered())
+	check("JSONIndent", i.JSONIndent())
+
+	b, err := i.JSONNested()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("JSONNested", b)
+}
+
+func TestExactPlurals(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pages": "0: No pages | 10: Ten pages | Single page | {n} pages", "items": "0:No items|{n} item|{n} items"}`))
+
+	assert(t, i.Tc("pages", 0), "No pages")
+	assert(t, i.Tc("pages", 10), "Ten pages")
+	assert(t, i.Tc("pages", 1), "Single page")
+	assert(t, i.Tc("pages", 5), "5 pages")
+	assert(t, i.T("pages"), "Single page")
+	assert(t, i.Tc("items", 0), "No items")
+	assert(t, i.Tc("items", 3), "3 items")
+	assert(t, len(i.Validate(nil)), 0)
+
+	ru, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian", "pages": "0: Нет страниц|{n} страница|{n} страницы|{n} страниц"}`))
+	assert(t, ru.Tc("pages", 0), "Нет страниц")
+	assert(t, ru.Tc("pages", 2), "2 страницы")
+	assert(t, ru.Tc("pages", 5), "5 страниц")
+
+	// Numbers with a colon aren't exact forms without two regular forms after them.
+	m, _ := New([]byte(`{"_.code": "en", "_.name": "English", "meeting": "10:30 meeting|10:30 meetings", "at": "0: none|10:30 one|10:30 many"}`))
+	assert(t, m.T("meeting"), "10:30 meeting")
+	assert(t, m.Tc("meeting", 1), "10:30 meeting")
+	assert(t, m.Tc("meeting", 10), "10:30 meetings")
+	assert(t, m.Tc("meeting", 2), "10:30 meetings")
+	assert(t, m.Tc("at", 0), "none")
+	assert(t, m.Tc("at", 10), "10:30 many")
+	assert(t, m.Tc("at", 1), "10:30 one")
+}
+
+func TestSetMissingFormat(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "page": "Page|Pages"}`))
+	i.SetMissingFormat(func(key string) string {
+		return "⟦" + key + "⟧"
+	})
+
+	assert(t, i.T("foo"), "Foo")
+	assert(t, i.T("bar"), "⟦bar⟧")
+	assert(t, i.Ts("bar", "a", "b"), "⟦bar⟧")
+	assert(t, i.Tc("bar", 2), "⟦bar⟧")
+	assert(t, i.Tc("page", 2), "Pages")
+	assert(t, i.TDefault("bar", "Bar"), "Bar")
+
+	i.SetMissingFormat(nil)
+	assert(t, i.T("bar"), "bar")
+}
+
+func TestNewFromGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json": `{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar"}`,
+		"b.json": `{"_.code": "en", "admin": {"title": "Admin"}, "bar": "Bar 2"}`,
+		"c.kv":   "baz=Baz\n",
+	}
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(b), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A parser for the .kv files with key=value lines.
+	RegisterParser(".KV", func(b []byte) (map[string]interface{}, error) {
+		m := make(map[string]interface{})
+		for _, l := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			k, v, _ := strings.Cut(l, "=")
+			m[k] = v
+		}
+		return m, nil
+	})
+	defer RegisterParser(".kv", nil)
+
+	i, err := NewFromGlob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.Code(), "en")
+	assert(t, i.T("foo"), "Foo")
+	assert(t, i.T("bar"), "Bar 2")
+	assert(t, i.T("admin.title"), "Admin")
+	assert(t, i.T("baz"), "Baz")
+	assert(t, i.Warnings(), []string{filepath.Join(dir, "b.json") + ": overwritten key bar"})
+
+	if err := os.WriteFile(filepath.Join(dir, "d.json"), []byte(`{"_.code": "de"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFromGlob(filepath.Join(dir, "*")); err == nil {
+		t.Fatal("expected error for inconsistent language codes")
+	}
+	if _, err := NewFromGlob(filepath.Join(dir, "*.xyz")); err == nil {
+		t.Fatal("expected error for no matching files")
+	}
+}
+
+func TestSetCodeNameMeta(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "_.region": "US", "page": "Page|Pages|Many pages"}`))
+
+	v, ok := i.Meta("region")
+	assert(t, v, "US")
+	assert(t, ok, true)
+	v, _ = i.Meta("_.region")
+	assert(t, v, "US")
+	_, ok = i.Meta("direction")
+	assert(t, ok, false)
+
+	i.SetCode("ru")
+	i.SetName("Russian")
+	assert(t, i.Code(), "ru")
+	assert(t, i.Name(), "Russian")
+	v, _ = i.Meta("code")
+	assert(t, v, "ru")
+	assert(t, i.Tc("page", 3), "Pages")
+	assert(t, i.Tc("page", 5), "Many pages")
+}
+
+func TestDirection(t *testing.T) {
+	for code, dir := range map[string]string{"en": "ltr", "ar": "rtl", "he": "rtl", "fa-IR": "rtl", "az-Arab": "rtl", "ja": "ltr", "xx": "ltr"} {
+		i, _ := New([]byte(`{"_.code": "` + code + `", "_.name": "Lang"}`))
+		assert(t, code+":"+i.Direction(), code+":"+dir)
+	}
+
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "_.direction": "RTL"}`))
+	assert(t, i.Direction(), "rtl")
+}
+
+func TestScalarValues(t *testing.T) {
+	i, err := New([]byte(`{"_.code": "en", "_.name": "English", "count": 5, "price": 1.50, "big": 1e3, "on": true, "a": {"n": -2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("count"), "5")
+	assert(t, i.T("price"), "1.50")
+	assert(t, i.T("big"), "1e3")
+	assert(t, i.T("on"), "true")
+	assert(t, i.T("a.n"), "-2")
+
+	_, err = New([]byte(`{"_.code": "en", "_.name": "English", "a": {"list": [5]}}`))
+	assert(t, err, "invalid candidate for a.list: 5")
+	_, err = New([]byte(`{"_.code": "en", "_.name": "English", "nil": null}`))
+	assert(t, err, "invalid value for nil: null")
+	_, err = New([]byte(`{"_.code": "en", "_.name": "English"} {}`))
+	if err == nil {
+		t.Fatal("expected error for trailing data")
+	}
+
+	m, err := NewFromMap(map[string]interface{}{"_.code": "en", "_.name": "English", "count": 5, "ratio": 0.5, "on": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, m.T("count"), "5")
+	assert(t, m.T("ratio"), "0.5")
+	assert(t, m.T("on"), "true")
+	assert(t, m.LoadMap(map[string]interface{}{"a": map[string]interface{}{"list": []interface{}{"x"}}}), "invalid value for a.list: arrays are not supported")
+}
+
+func TestPluralCache(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "page": "Page|Pages"}`))
+
+	assert(t, i.Tc("page", 2), "Pages")
+	_ = i.Set("page", "Sheet|Sheets")
+	assert(t, i.Tc("page", 2), "Sheets")
+	_ = i.Load([]byte(`{"page": "0: None|Leaf|Leaves"}`))
+	assert(t, i.Tc("page", 0), "None")
+	assert(t, i.Tc("page", 2), "Leaves")
+	assert(t, i.pluralCache != nil, true)
+
+	_ = i.Delete("page")
+	assert(t, i.pluralCache == nil, true)
+}
+
+func BenchmarkTc(b *testing.B) {
+	i, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian", "pages": "0: No pages | {n} страница | {n} страницы | {n} страниц", "items": "Item|Items"}`))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		i.Tc("pages", n%10)
+		i.Tc("items", n%3)
+		i.P("items")
+	}
+}
+
+func BenchmarkTs(b *testing.B) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "folder": "Inbox", "mixedMsg": "Hello {name}, you have {count} new {items} in {folder} from {sender} since {date}, {{literal}} {unknown}"}`))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		i.Ts("mixedMsg", "name", "John", "count", 5, "items", "messages", "folder", "{folder}", "sender", "Jane", "date", "Monday")
+	}
+}
+
+func TestNewFromReader(t *testing.T) {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, _ = w.Write([]byte(`{"_.code": "en", "_.name": "English", "a": {"b": "B", "c": {"d": "D"}}, "foo": "Foo"}`))
+	_ = w.Close()
+
+	r, err := gzip.NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := NewFromReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("a.c.d"), "D")
+	assert(t, string(i.JSONOrdered()), `{"_.code":"en","_.name":"English","a.b":"B","a.c.d":"D","foo":"Foo"}`)
+
+	for _, in := range []string{`["a"]`, `{"_.code": "en", "_.name": "English", "a": }`, `{"_.code": "en"`} {
+		if _, err := NewFromReader(bytes.NewReader([]byte(in))); err == nil {
+			t.Fatalf("expected error for %s", in)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "product": "Acme"}`))
+
+	assert(t, i.Interpolate("{count} results for {query} in {where} {{x}} {missing}", "count", 5, "query", "go", "where", "{product}"),
+		"5 results for go in Acme {x} {missing}")
+	assert(t, i.Interpolate("A | B {n}", "n", 1), "A | B 1")
+	assert(t, i.Interpolate("{a}", "a"), "{a}: invalid arguments")
+}
+
+func TestSetPluralSeparator(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "label": "Name | Description", "files": "0: No files || {n} file || {n} files", "pronoun": "male: He || other: They"}`))
+
+	assert(t, i.T("label"), "Name")
+	i.SetPluralSeparator("||")
+	assert(t, i.T("label"), "Name | Description")
+	assert(t, i.Tc("label", 2), "Name | Description")
+	assert(t, i.Tc("files", 0), "No files")
+	assert(t, i.Tc("files", 1), "1 file")
+	assert(t, i.Tc("files", 3), "3 files")
+	assert(t, i.P("files"), "{n} files")
+	assert(t, i.Forms("files"), []string{"0: No files", "{n} file", "{n} files"})
+	assert(t, i.TSelect("pronoun", "female"), "They")
+	assert(t, len(i.Validate(nil)), 0)
+
+	i.SetPluralSeparator("")
+	assert(t, i.T("label"), "Name")
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	i, err := New([]byte(`{"_.code": "en", "_.name": "English", "pageTitle": "One", "a.b": "X", "pageTitle": "Two", "a": {"b": "Y"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("pageTitle"), "Two")
+	assert(t, i.T("a.b"), "Y")
+	assert(t, i.Warnings(), []string{"duplicate key pageTitle", "duplicate key a.b"})
+
+	_ = i.Load([]byte(`{"foo": "1", "foo": "2"}`))
+	assert(t, len(i.Warnings()), 3)
+
+	c, _ := New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	assert(t, len(c.Warnings()), 0)
+}
+
+func TestTMap(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "save": "Save", "cancel": "Cancel", "page": "Page|Pages"}`))
+
+	assert(t, i.TMap("save", "cancel", "page", "missing"), map[string]string{"save": "Save", "cancel": "Cancel", "page": "Page", "missing": "missing"})
+	assert(t, i.TMapFound("save", "missing"), map[string]string{"save": "Save"})
+}
+
+func TestLookup(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar"}`))
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "German", "foo": "Fu|Fus"}`))
+	de.SetFallback(en)
+
+	v, ok, src := de.Lookup("foo")
+	assert(t, v+":"+src, "Fu:primary")
+	assert(t, ok, true)
+
+	v, ok, src = de.Lookup("bar")
+	assert(t, v+":"+src, "Bar:fallback")
+	assert(t, ok, true)
+
+	v, ok, src = de.Lookup("baz")
+	assert(t, v+":"+src, "baz:missing")
+	assert(t, ok, false)
+}
+
+func TestDescription(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "save": "Save", "_meta.save": "Button label, keep it short", "_meta": {"page": "Pagination"}, "page": "Page|Pages"}`))
+
+	assert(t, i.Description("save"), "Button label, keep it short")
+	assert(t, i.Description("page"), "Pagination")
+	assert(t, i.Description("missing"), "")
+	assert(t, i.T("_meta.save"), "_meta.save")
+	assert(t, i.Keys(), []string{"page", "save"})
+	assert(t, string(i.JSONOrdered()), `{"_.code":"en","_.name":"English","save":"Save","_meta.save":"Button label, keep it short","page":"Page|Pages","_meta.page":"Pagination"}`)
+
+	_ = i.Load([]byte(`{"_meta.save": "Updated"}`))
+	assert(t, i.Description("save"), "Updated")
+
+	o, _ := New([]byte(`{"_.code": "en", "_.name": "English", "_meta.new": "New key"}`))
+	_ = i.Merge(o)
+	assert(t, i.Description("new"), "New key")
+}
+
+func TestSource(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "save": "Save", "_src.save": "human", "_src": {"page": "mt:deepl"}, "page": "Page|Pages"}`))
+
+	assert(t, i.Source("save"), "human")
+	assert(t, i.Source("page"), "mt:deepl")
+	assert(t, i.Source("missing"), "")
+	assert(t, i.T("_src.save"), "_src.save")
+	assert(t, i.Keys(), []string{"page", "save"})
+
+	// Human sourced values are never overwritten by machine translated ones.
+	preferHuman := func(key, existing, incoming string) string {
+		if i.Source(key) == "human" {
+			return existing
+		}
+		return incoming
+	}
+	err := i.LoadWith([]byte(`{"save": "Store", "_src.save": "mt:deepl", "page": "Page|Pages!", "_src.page": "human"}`), preferHuman)
+	assert(t, err, nil)
+	assert(t, i.T("save"), "Save")
+	assert(t, i.Source("save"), "human")
+	assert(t, i.T("page"), "Page")
+	assert(t, i.Source("page"), "human")
+	assert(t, i.Keys(), []string{"page", "save"})
+	assert(t, i.Clone().Source("save"), "human")
+
+	o, _ := New([]byte(`{"_.code": "en", "_.name": "English", "new": "New", "_src.new": "import.csv"}`))
+	_ = i.Merge(o)
+	assert(t, i.Source("new"), "import.csv")
+}
+
+func TestUnused(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "save": "Save", "cancel": "Cancel", "old": "Old", "older": "Older"}`))
+
+	assert(t, i.Unused([]string{"save", "cancel", "notInMap"}), []string{"old", "older"})
+	assert(t, i.Unused([]string{"save", "cancel", "old", "older"}), []string{})
+}
+
+func TestSetGlobals(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "contact": "Contact {app} at {supportEmail}", "item": "{n} {app} item|{n} {app} items", "icu": "{app}: {n, plural, one {# item} other {# items}}",
+		"unit": "{n} {unit} {other}|{n} {unit}s {other}", "place": "{n}st {app}|{n}nd {app}|{n}rd {app}|{n}th {app}", "cart.one": "{n} {app} item"}`))
+	i.SetGlobals(map[string]interface{}{"app": "listmonk", "supportEmail": "help@site.com", "unit": "page"})
+
+	assert(t, i.Ts("contact"), "Contact listmonk at help@site.com")
+	assert(t, i.Ts("contact", "app", "dictpress"), "Contact dictpress at help@site.com")
+	assert(t, i.Tsm("contact", nil), "Contact listmonk at help@site.com")
+	assert(t, i.Tc("item", 2), "2 listmonk items")
+	assert(t, i.Tc("unit", 3), "3 pages {other}")
+	assert(t, i.Tco("place", 2), "2nd listmonk")
+	assert(t, i.TcKey("cart", 1), "1 listmonk item")
+	assert(t, i.TICU("icu", map[string]interface{}{"n": 1}), "listmonk: 1 item")
+	assert(t, i.TICU("icu", map[string]interface{}{"n": 2, "app": "x"}), "x: 2 items")
+
+	i.SetGlobals(nil)
+	assert(t, i.Ts("contact"), "Contact {app} at {supportEmail}")
+}
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if w.n+len(p) > 8 {
+		return 0, errors.New("short write")
+	}
+	w.n += len(p)
+	return len(p), nil
+}
+
+func TestFT(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "folder": "Inbox", "msg": "Hello {name}, {{literal}} in {folder} {unknown}|Hellos"}`))
+
+	var b bytes.Buffer
+	n, err := i.FT(&b, "msg", "name", "<John>", "folder", "{folder}")
+	assert(t, err, nil)
+	assert(t, b.String(), i.Ts("msg", "name", "<John>", "folder", "{folder}"))
+	assert(t, n, b.Len())
+
+	b.Reset()
+	_, _ = i.FT(&b, "missing")
+	assert(t, b.String(), "missing")
+
+	_, err = i.FT(&b, "msg", "name")
+	assert(t, errors.Is(err, ErrInvalidParams), true)
+
+	n, err = i.FT(&errWriter{}, "msg", "name", "John")
+	assert(t, err.Error(), "short write")
+	assert(t, n, 6)
+}
+
+func BenchmarkFT(b *testing.B) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "folder": "Inbox", "mixedMsg": "Hello {name}, you have {count} new {items} in {folder} from {sender} since {date}, {{literal}} {unknown}"}`))
+
+	var w bytes.Buffer
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		w.Reset()
+		_, _ = i.FT(&w, "mixedMsg", "name", "John", "count", 5, "items", "messages", "folder", "{folder}", "sender", "Jane", "date", "Monday")
+	}
+}
+
+func TestCopyOnWrite(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "folder": "Inbox", "msg": "Moved to {dest}"}`))
+
+	// The map is modified while Ts() is substituting the params, and the
+	// nested {folder} reference still resolves with the map Ts() started with.
+	type dest struct{}
+	i.SetStringer(func(v interface{}) (string, bool) {
+		if _, ok := v.(dest); ok {
+			_ = i.Set("folder", "Archive")
+			return "{folder}", true
+		}
+		return "", false
+	})
+	assert(t, i.Ts("msg", "dest", dest{}), "Moved to Inbox")
+	assert(t, i.Ts("msg", "dest", dest{}), "Moved to Archive")
+
+	raw := i.Raw()
+	snap := i.view()
+	_ = i.Delete("folder")
+	_, ok := snap.get("folder")
+	assert(t, ok, true)
+	assert(t, i.Has("folder"), false)
+	assert(t, raw["folder"], "Archive")
+}
+
+func TestTcf(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "hours": "{n} hour|{n} hours", "liters": "0: No liters|Zero liters|{n} liter|{n} liters"}`))
+	assert(t, i.Tcf("hours", 1.0), "1 hour")
+	assert(t, i.Tcf("hours", 1.5), "1.5 hours")
+	assert(t, i.Tcf("hours", 0.5), "0.5 hours")
+	assert(t, i.Tcf("hours", -1), "-1 hour")
+	assert(t, i.Tcf("liters", 0), "No liters")
+	assert(t, i.Tcf("liters", 0.5), "0.5 liters")
+	assert(t, i.Tcf("liters", 1), "1 liter")
+
+	i.SetNumberFormat(true)
+	assert(t, i.Tcf("hours", 1234.5), "1,234.5 hours")
+
+	for _, c := range []struct {
+		code, value string
+		n           float64
+		expected    string
+	}{
+		{"fr", "heure|heures", 1.5, "heure"},
+		{"fr", "heure|heures", 2.5, "heures"},
+		{"pt", "hora|horas", 0, "hora"},
+		{"pt-BR", "hora|horas", 1.5, "hora"},
+		{"pt-PT", "hora|horas", 0, "horas"},
+		{"pt_PT", "hora|horas", 1.5, "horas"},
+		{"hi", "one|other", 0, "one"},
+		{"hi", "one|other", 0.5, "one"},
+		{"hi", "one|other", 1.5, "other"},
+		{"bn", "one|other", 2, "other"},
+		{"fa", "one|other", 1, "one"},
+		{"zu", "one|other", 0.25, "one"},
+		{"kn", "one|other", 0, "one"},
+		{"kn", "one|other", 0.5, "one"},
+		{"kn", "one|other", 2, "other"},
+		{"is", "dagur|dagar", 1, "dagur"},
+		{"is", "dagur|dagar", 21, "dagur"},
+		{"is", "dagur|dagar", 11, "dagar"},
+		{"is", "dagur|dagar", 0.1, "dagur"},
+		{"is", "dagur|dagar", 1.5, "dagar"},
+		{"is", "dagur|dagar", 31.1, "dagur"},
+		{"ru", "час|часа|часов", 1.5, "часа"},
+		{"ru", "час|часа|часов", 5, "часов"},
+		{"hr", "one|few|other", 1.1, "one"},
+		{"hr", "one|few|other", 1.2, "few"},
+		{"hr", "one|few|other", 1.5, "other"},
+		{"sl", "one|two|few|other", 1.5, "few"},
+		{"ro", "one|few|other", 1.5, "few"},
+		{"he", "one|two|other", 0.5, "one"},
+		{"he", "one|two|other", 2.5, "other"},
+		{"lv", "zero|one|other", 0.1, "one"},
+		{"lv", "zero|one|other", 0.15, "zero"},
+		{"lv", "zero|one|other", 0.2, "other"},
+		{"ja", "時間", 1.5, "時間"},
+		{"ru", "hour|hours", 1.5, "hours"},
+	} {
+		l, _ := New([]byte(`{"_.code": "` + c.code + `", "_.name": "Lang", "v": "` + c.value + `"}`))
+		assert(t, c.code+":"+l.Tcf("v", c.n), c.code+":"+c.expected)
+	}
+
+	is, _ := New([]byte(`{"_.code": "is", "_.name": "Íslenska", "days": "{n} dagur|{n} dagar"}`))
+	assert(t, is.Tc("days", 21), "21 dagur")
+	assert(t, is.Tc("days", 111), "111 dagar")
+	kn, _ := New([]byte(`{"_.code": "kn", "_.name": "Kannada", "v": "one|other"}`))
+	assert(t, kn.Tc("v", 0), "one")
+}
+
+func TestStubMissing(t *testing.T) {
+	ref, _ := New([]byte(`{"_.code": "en", "_.name": "English", "_.listSep": ", ", "_.direction": "ltr", "save": "Save", "_meta.save": "Button label", "page": "Page|Pages", "msg": "<b>{name}</b>"}`))
+	i, _ := New([]byte(`{"_.code": "de", "_.name": "Deutsch", "_.direction": "ltr", "page": "Seite|Seiten"}`))
+
+	b := i.StubMissing(ref)
+	assert(t, string(b), `{
+	"_.code": "de",
+	"_meta._.code": "Meta key, update it for the language",
+	"_.name": "Deutsch",
+	"_meta._.name": "Meta key, update it for the language",
+	"_.listSep": ", ",
+	"_meta._.listSep": "Meta key, update it for the language",
+	"save": "Save",
+	"_meta.save": "Button label",
+	"msg": "<b>{name}</b>"
+}`)
+
+	stub, err := New(b)
+	assert(t, err, nil)
+	assert(t, stub.Code(), "de")
+	assert(t, stub.Description("save"), "Button label")
+
+	_ = i.Load(b)
+	assert(t, len(i.MissingFrom(ref)), 0)
+}
+
+func TestParamTypes(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English",
+		"pageVars": "The page is named {name} and has {count} items",
+		"_types": {"pageVars": "name: string, count: number", "bad": "count, n: float"}}`))
+
+	assert(t, i.Has("_types.pageVars"), false)
+	assert(t, i.Warnings(), []string{`invalid param type declaration for bad: "count"`, "unknown param type for bad: n: float"})
+
+	s, err := i.TsE("pageVars", "name", "Foo", "count", 1234)
+	assert(t, err, nil)
+	assert(t, s, "The page is named Foo and has 1234 items")
+
+	s, err = i.TsE("pageVars", "name", 1, "count", "1234")
+	assert(t, errors.Is(err, ErrParamType), true)
+	assert(t, err.Error(), "pageVars: invalid param type: name: expected string, got int; count: expected number, got string")
+	assert(t, s, "The page is named 1 and has 1234 items")
+
+	// Declarations in the fallback apply to the keys resolved from it.
+	de, _ := New([]byte(`{"_.code": "de", "_.name": "Deutsch"}`))
+	de.SetFallback(i)
+	_, err = de.TsE("pageVars", "name", "Foo", "count", "x")
+	assert(t, errors.Is(err, ErrParamType), true)
+
+	_ = de.Load([]byte(`{"_types.pageVars": "count: int"}`))
+	_, err = de.TsE("pageVars", "name", 1, "count", 2)
+	assert(t, err, nil)
+
+	m, _ := New([]byte(`{"_.code": "de", "_.name": "Deutsch", "pageVars": "{count} Seiten"}`))
+	_ = m.Merge(de)
+	_, err = m.TsE("pageVars", "count", 1.5)
+	assert(t, errors.Is(err, ErrParamType), true)
+}
+
+func TestTsLazy(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "msg": "Hello {name}"}`))
+
+	var calls int
+	i.SetStringer(func(v interface{}) (string, bool) {
+		calls++
+		return "", false
+	})
+
+	s := i.TsLazy("msg", "name", "Foo")
+	assert(t, calls, 0)
+
+	_ = i.Set("msg", "Hi {name}")
+	assert(t, s.String(), "Hi Foo")
+	assert(t, fmt.Sprintf("%v", s), "Hi Foo")
+	assert(t, calls, 2)
+	assert(t, i.TsLazy("msg", "name").String(), "msg: invalid arguments")
+}
+
+func TestSetKeyPluralForms(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian",
+		"hours": "{count} час|{count} часа|{count} часов",
+		"brand": "Acme Box|Acme Boxes|Acme Box Mega|Acme Box Ultra|Acme Box Max",
+		"badge": "Top seller|Top sellers"}`))
+	assert(t, i.Tc("hours", 5), "5 часов")
+	assert(t, i.Validate(nil), "[brand: 5 plural forms, expected 2, 3, or 4]")
+
+	i.SetKeyPluralForms("hours", 2)
+	assert(t, i.Tc("hours", 1), "1 час")
+	assert(t, i.Tc("hours", 5), "5 часа")
+	assert(t, i.Tcf("hours", 1.5), "1.5 часа")
+
+	i.SetKeyPluralForms("brand", 1)
+	assert(t, i.Tc("brand", 1), "Acme Box")
+	assert(t, i.Tc("brand", 5), "Acme Box")
+
+	i.SetKeyPluralForms("brand", 5)
+	i.SetKeyPluralForms("badge", 3)
+	assert(t, i.Validate(nil), "[badge: 2 plural forms, expected 3 hours: 3 plural forms, expected 2]")
+	assert(t, i.Clone().Tc("hours", 5), "5 часа")
+
+	i.SetKeyPluralForms("hours", 0)
+	i.SetKeyPluralForms("badge", 0)
+	assert(t, i.Tc("hours", 5), "5 часов")
+	assert(t, i.Validate(nil), "[]")
+}
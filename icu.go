@@ -0,0 +1,434 @@
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/number"
+)
+
+// icuNode is a node in a parsed ICU MessageFormat message.
+type icuNode struct {
+	// Literal text, if the node isn't an argument or a # token.
+	text string
+
+	// The # token in a plural branch that's replaced with the count.
+	hash bool
+
+	// {arg, typ, style} argument, where typ is "", number, plural, or select.
+	arg    string
+	typ    string
+	style  string
+	offset float64
+	opts   []icuOption
+}
+
+// icuOption is a branch in a plural or select argument, eg: one {# item}.
+type icuOption struct {
+	key string
+	msg []icuNode
+}
+
+// icuParser is a recursive descent parser for a subset of ICU MessageFormat.
+type icuParser struct {
+	s   string
+	pos int
+}
+
+// TICU returns the translation for the given key as an ICU MessageFormat message
+// evaluated against the given params, for reusing catalogs shared with ICU based
+// (eg: Javascript) applications. Simple {arg}, {arg, number[, integer|percent]},
+// {arg, plural, ...} and {arg, select, ...} arguments, with nesting, are supported.
+// Plural categories are selected as per the CLDR rules of the instance's language,
+// =N branches match n exactly, and # is substituted with the (offset) count.
+// eg: "{count, plural, =0 {No items} one {# item} other {# items}}"
+//
+// Arguments with missing params are left as-is, and if the message is malformed,
+// it's returned unevaluated.
+func (i *I18n) TICU(key string, params map[string]interface{}) string {
+	s, src, ok := i.get(key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	nodes, err := parseICU(s)
+	if err != nil {
+		return s
+	}
+
+	i.mu.RLock()
+	globals := i.globals
+	i.mu.RUnlock()
+
+	// Params override the globals with the same name.
+	if globals != nil {
+		p := make(map[string]interface{}, len(globals)+len(params))
+		for k, v := range globals {
+			p[k] = v
+		}
+		for k, v := range params {
+			p[k] = v
+		}
+		params = p
+	}
+
+	return src.evalICU(nodes, params, "#")
+}
+
+// parseICU parses an ICU MessageFormat message.
+func parseICU(s string) ([]icuNode, error) {
+	p := &icuParser{s: s}
+	nodes, err := p.message(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected } at %d", p.pos)
+	}
+
+	return nodes, nil
+}
+
+// message parses text and arguments up to the end of the string or an
+// unmatched closing brace. # is a token only inside plural branches.
+func (p *icuParser) message(inPlural bool) ([]icuNode, error) {
+	var (
+		nodes []icuNode
+		text  strings.Builder
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, icuNode{text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == '}':
+			flush()
+			return nodes, nil
+		case c == '{':
+			flush()
+			n, err := p.argument(inPlural)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		case c == '#' && inPlural:
+			flush()
+			nodes = append(nodes, icuNode{hash: true})
+			p.pos++
+		case c == '\'':
+			text.WriteString(p.quoted())
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	flush()
+	return nodes, nil
+}
+
+// quoted parses an apostrophe at the current position. A doubled apostrophe is
+// a literal apostrophe and an apostrophe followed by a special character starts
+// a quoted literal that runs up to the next single apostrophe.
+func (p *icuParser) quoted() string {
+	p.pos++
+	if p.pos >= len(p.s) {
+		return "'"
+	}
+
+	switch p.s[p.pos] {
+	case '\'':
+		p.pos++
+		return "'"
+	case '{', '}', '#':
+	default:
+		return "'"
+	}
+
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		if c != '\'' {
+			b.WriteByte(c)
+			continue
+		}
+
+		if p.pos < len(p.s) && p.s[p.pos] == '\'' {
+			b.WriteByte('\'')
+			p.pos++
+			continue
+		}
+		break
+	}
+
+	return b.String()
+}
+
+// argument parses a {arg[, type[, style]]} argument at the current position.
+func (p *icuParser) argument(inPlural bool) (icuNode, error) {
+	start := p.pos
+	p.pos++
+
+	n := icuNode{arg: p.word()}
+	if n.arg == "" {
+		return n, fmt.Errorf("missing argument name at %d", start)
+	}
+
+	if p.consume('}') {
+		return n, nil
+	}
+	if !p.consume(',') {
+		return n, fmt.Errorf("invalid argument %s at %d", n.arg, start)
+	}
+
+	n.typ = p.word()
+	switch n.typ {
+	case "number":
+		if p.consume(',') {
+			n.style = p.word()
+		}
+		if !p.consume('}') {
+			return n, fmt.Errorf("unterminated argument %s at %d", n.arg, start)
+		}
+		return n, nil
+
+	case "plural", "select":
+		if !p.consume(',') {
+			return n, fmt.Errorf("missing %s options for %s at %d", n.typ, n.arg, start)
+		}
+	default:
+		return n, fmt.Errorf("unknown argument type %s at %d", n.typ, start)
+	}
+
+	if n.typ == "plural" {
+		inPlural = true
+
+		p.skipSpace()
+		if strings.HasPrefix(p.s[p.pos:], "offset:") {
+			p.pos += len("offset:")
+			off, err := strconv.ParseFloat(p.word(), 64)
+			if err != nil {
+				return n, fmt.Errorf("invalid offset for %s at %d", n.arg, start)
+			}
+			n.offset = off
+		}
+	}
+
+	for {
+		if p.consume('}') {
+			break
+		}
+
+		key := p.word()
+		if key == "" || !p.consume('{') {
+			return n, fmt.Errorf("invalid %s option for %s at %d", n.typ, n.arg, start)
+		}
+
+		msg, err := p.message(inPlural)
+		if err != nil {
+			return n, err
+		}
+		if !p.consume('}') {
+			return n, fmt.Errorf("unterminated %s option %s for %s at %d", n.typ, key, n.arg, start)
+		}
+
+		n.opts = append(n.opts, icuOption{key: key, msg: msg})
+	}
+
+	if len(n.opts) == 0 {
+		return n, errors.New("no options for " + n.arg)
+	}
+
+	return n, nil
+}
+
+// word skips whitespace and returns the following run of characters
+// up to a whitespace or a special character.
+func (p *icuParser) word() string {
+	p.skipSpace()
+
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune("{},", rune(p.s[p.pos])) && !unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+
+	return p.s[start:p.pos]
+}
+
+// consume skips whitespace and consumes c if it's the next character.
+func (p *icuParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *icuParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// evalICU evaluates parsed ICU nodes against the params. hash is the
+// value of the # token of the innermost plural argument.
+func (i *I18n) evalICU(nodes []icuNode, params map[string]interface{}, hash string) string {
+	i.mu.RLock()
+	esc, numFormat, bidi := i.htmlEscape, i.numFormat, i.bidiIsolate
+	i.mu.RUnlock()
+	code, p := i.locale()
+
+	var b strings.Builder
+	for _, n := range nodes {
+		switch {
+		case n.hash:
+			b.WriteString(hash)
+			continue
+		case n.arg == "":
+			b.WriteString(n.text)
+			continue
+		}
+
+		v, ok := params[n.arg]
+		if !ok && n.typ != "plural" && n.typ != "select" {
+			b.WriteString("{" + n.arg + "}")
+			continue
+		}
+
+		switch n.typ {
+		case "":
+			val := i.formatValue(v, numFormat, nil)
+			if esc {
+				val = html.EscapeString(val)
+			}
+			if bidi {
+				val = bidiIsolate(val)
+			}
+			b.WriteString(val)
+
+		case "number":
+			f, ok := toFloat(v)
+			if !ok {
+				b.WriteString(toString(v))
+				continue
+			}
+
+			switch n.style {
+			case "percent":
+				b.WriteString(p.Sprint(number.Percent(f)))
+			case "integer":
+				b.WriteString(p.Sprint(number.Decimal(f, number.MaxFractionDigits(0))))
+			default:
+				b.WriteString(p.Sprint(number.Decimal(f)))
+			}
+
+		case "select":
+			var sel string
+			if ok {
+				sel = toString(v)
+			}
+			if o, ok := selectOption(n.opts, sel); ok {
+				b.WriteString(i.evalICU(o.msg, params, hash))
+			}
+
+		case "plural":
+			var (
+				sel = "other"
+				h   = hash
+			)
+			if f, ok := toFloat(v); ok {
+				f -= n.offset
+				h = p.Sprint(number.Decimal(f))
+
+				if o, ok := findOption(n.opts, "="+strconv.FormatFloat(f+n.offset, 'f', -1, 64)); ok {
+					b.WriteString(i.evalICU(o.msg, params, h))
+					continue
+				}
+				if f == math.Trunc(f) {
+					r := getPluralRule(code)
+					sel = r.categories[r.index(int(math.Abs(f)))]
+				}
+			} else if ok {
+				h = toString(v)
+			}
+
+			if o, ok := selectOption(n.opts, sel); ok {
+				b.WriteString(i.evalICU(o.msg, params, h))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// findOption returns the option with the given key.
+func findOption(opts []icuOption, key string) (icuOption, bool) {
+	for _, o := range opts {
+		if o.key == key {
+			return o, true
+		}
+	}
+
+	return icuOption{}, false
+}
+
+// selectOption returns the option with the given key, or the "other" option.
+func selectOption(opts []icuOption, key string) (icuOption, bool) {
+	if o, ok := findOption(opts, key); ok {
+		return o, true
+	}
+
+	return findOption(opts, "other")
+}
+
+// toFloat returns the float value of a numeric or a numeric string param value.
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
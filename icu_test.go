@@ -0,0 +1,42 @@
+package i18n
+
+import "testing"
+
+func TestTICU(t *testing.T) {
+	i, err := New([]byte(`{"_.code": "en", "_.name": "English",
+		"items": "{count, plural, =0 {No items} one {# item} other {# items}}",
+		"invite": "{gender, select, female {She invited {count, plural, one {one guest} other {# guests}}} male {He invited {count, plural, one {one guest} other {# guests}}} other {They invited # guests}}",
+		"others": "{n, plural, offset:1 =0 {Nobody} =1 {{name}} one {{name} and one other} other {{name} and # others}}",
+		"num": "Total: {n, number} ({p, number, percent})",
+		"quoted": "It''s '{literal}' {name}",
+		"bad": "{count, plural, one {# item}"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.TICU("items", map[string]interface{}{"count": 0}), "No items")
+	assert(t, i.TICU("items", map[string]interface{}{"count": 1}), "1 item")
+	assert(t, i.TICU("items", map[string]interface{}{"count": 1234}), "1,234 items")
+	assert(t, i.TICU("items", nil), "# items")
+
+	assert(t, i.TICU("invite", map[string]interface{}{"gender": "female", "count": 1}), "She invited one guest")
+	assert(t, i.TICU("invite", map[string]interface{}{"gender": "male", "count": 3}), "He invited 3 guests")
+	assert(t, i.TICU("invite", map[string]interface{}{"gender": "x", "count": 3}), "They invited # guests")
+
+	assert(t, i.TICU("others", map[string]interface{}{"n": 1, "name": "Ann"}), "Ann")
+	assert(t, i.TICU("others", map[string]interface{}{"n": 2, "name": "Ann"}), "Ann and one other")
+	assert(t, i.TICU("others", map[string]interface{}{"n": 5, "name": "Ann"}), "Ann and 4 others")
+
+	assert(t, i.TICU("num", map[string]interface{}{"n": 1234.5, "p": 0.25}), "Total: 1,234.5 (25%)")
+	assert(t, i.TICU("quoted", map[string]interface{}{"name": "Ann"}), "It's {literal} Ann")
+	assert(t, i.TICU("quoted", nil), "It's {literal} {name}")
+	assert(t, i.TICU("bad", map[string]interface{}{"count": 1}), "{count, plural, one {# item}")
+	assert(t, i.TICU("missing", nil), "missing")
+
+	ru, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian", "files": "{n, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}"}`))
+	assert(t, ru.TICU("files", map[string]interface{}{"n": 1}), "1 файл")
+	assert(t, ru.TICU("files", map[string]interface{}{"n": 3}), "3 файла")
+	assert(t, ru.TICU("files", map[string]interface{}{"n": 11}), "11 файлов")
+	assert(t, ru.TICU("files", map[string]interface{}{"n": 1.5}), "1,5 файла")
+}
@@ -0,0 +1,83 @@
+package i18n
+
+// stripJSONC returns a copy of JSONC (JSON with comments) b with the // line and
+// /* block */ comments, and the trailing commas before closing braces and brackets,
+// blanked out with spaces. The offsets in decoding errors remain those in b as its
+// length and line breaks are retained.
+func stripJSONC(b []byte) []byte {
+	out := make([]byte, len(b))
+	copy(out, b)
+
+	blank := func(from, to int) {
+		for n := from; n < to; n++ {
+			if out[n] != '\n' && out[n] != '\r' {
+				out[n] = ' '
+			}
+		}
+	}
+
+	// Comments.
+	for n := 0; n < len(out); n++ {
+		switch {
+		case out[n] == '"':
+			n = skipString(out, n)
+
+		case out[n] == '/' && n+1 < len(out) && out[n+1] == '/':
+			end := n
+			for end < len(out) && out[end] != '\n' {
+				end++
+			}
+			blank(n, end)
+			n = end
+
+		case out[n] == '/' && n+1 < len(out) && out[n+1] == '*':
+			end := n + 2
+			for end < len(out) && !(out[end] == '*' && end+1 < len(out) && out[end+1] == '/') {
+				end++
+			}
+			if end < len(out) {
+				end += 2
+			}
+			blank(n, end)
+			n = end - 1
+		}
+	}
+
+	// Trailing commas.
+	for n := 0; n < len(out); n++ {
+		switch out[n] {
+		case '"':
+			n = skipString(out, n)
+
+		case ',':
+			next := n + 1
+			for next < len(out) && isJSONSpace(out[next]) {
+				next++
+			}
+			if next < len(out) && (out[next] == '}' || out[next] == ']') {
+				out[n] = ' '
+			}
+		}
+	}
+
+	return out
+}
+
+// skipString returns the index of the closing quote of the JSON string
+// that starts at the quote at n, or the end of b if it's unterminated.
+func skipString(b []byte, n int) int {
+	for n++; n < len(b); n++ {
+		switch b[n] {
+		case '\\':
+			n++
+		case '"':
+			return n
+		}
+	}
+
+	return len(b)
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,46 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithJSONC(t *testing.T) {
+	b := []byte(`{
+	// Language meta.
+	"_.code": "en",
+	"_.name": "English", /* The display name. */
+
+	"url": "https://listmonk.app", // Not a comment.
+	"msg": "a /* b */ c \" // d",
+	"nested": {
+		"a": "A",
+		/* Multi line
+		   comment. */
+		"b": "B",
+	},
+}`)
+
+	_, err := New(b)
+	assert(t, err != nil, true)
+
+	i, err := New(b, WithJSONC())
+	assert(t, err, nil)
+	assert(t, i.T("url"), "https://listmonk.app")
+	assert(t, i.T("msg"), `a /* b */ c " // d`)
+	assert(t, i.T("nested.b"), "B")
+	assert(t, i.Keys(), []string{"msg", "nested.a", "nested.b", "url"})
+
+	i, err = NewFromReader(strings.NewReader(string(b)), WithJSONC())
+	assert(t, err, nil)
+	assert(t, i.T("nested.a"), "A")
+
+	err = i.Load([]byte(`{"x": "X", /* unterminated`))
+	assert(t, err != nil, true)
+	err = i.Load([]byte(`{"x": "X", // comment
+	}`))
+	assert(t, err, nil)
+	assert(t, i.T("x"), "X")
+
+	assert(t, string(stripJSONC([]byte("{\"a\": [1, 2,],}"))), "{\"a\": [1, 2 ] }")
+}
@@ -0,0 +1,68 @@
+package i18n
+
+// Option customizes an I18n instance created with New() and the other
+// constructors, eg: New(b, WithMetaPrefix("@@")). Options are applied before
+// the language map is loaded and the instance is returned, unlike the SetX()
+// setters that may race with lookups on an instance that's in use.
+type Option func(*I18n)
+
+// WithMetaPrefix sets the prefix of the special meta keys, which is _. by
+// default, eg: with @@, the language code and name are read from @@code and
+// @@name, and Keys() excludes the keys starting with @@. The prefixes of the
+// descriptions, sources, and param types of keys are derived from it, eg:
+// @@meta., @@src., and @@types. instead of _meta., _src., and _types.
+func WithMetaPrefix(prefix string) Option {
+	return func(i *I18n) {
+		i.meta = prefix
+	}
+}
+
+// WithFallback sets the fallback instance whose translations are used for keys
+// that are missing in the instance. See SetFallback().
+func WithFallback(fb *I18n) Option {
+	return func(i *I18n) {
+		i.fallback = fb
+	}
+}
+
+// WithDelimiters sets the left and right delimiters of the params in values,
+// which are { and } by default, eg: with {{ and }}, Ts() substitutes {{name}}.
+// A doubled delimiter is rendered as a literal delimiter as with the braces.
+// If either of the delimiters is empty, the default delimiters are used.
+func WithDelimiters(left, right string) Option {
+	return func(i *I18n) {
+		if left == "" || right == "" {
+			i.delims = defaultDelims
+			return
+		}
+		i.delims = delims{left, right}
+	}
+}
+
+// WithStrict enables the strict mode. See SetStrict().
+func WithStrict() Option {
+	return func(i *I18n) {
+		i.strict = true
+	}
+}
+
+// WithPluralSeparator sets the separator of the plural forms in values.
+// See SetPluralSeparator().
+func WithPluralSeparator(sep string) Option {
+	return func(i *I18n) {
+		if sep == "" {
+			sep = "|"
+		}
+		i.sep = sep
+	}
+}
+
+// WithJSONC enables comments in JSON language maps, eg: notes for translators,
+// loaded into the instance with New(), Load(), Reload() and the other JSON
+// functions. // line and /* block */ comments, and trailing commas in objects
+// and arrays, are stripped before the maps are parsed.
+func WithJSONC() Option {
+	return func(i *I18n) {
+		i.jsonc = true
+	}
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithMetaPrefix(t *testing.T) {
+	b := []byte(`{"@@code": "de", "@@name": "Deutsch", "@@listAnd": "und", "_.code": "x", "page": "Seite|Seiten"}`)
+	i, err := New(b, WithMetaPrefix("@@"))
+	assert(t, err, nil)
+	assert(t, i.Code(), "de")
+	assert(t, i.Name(), "Deutsch")
+	assert(t, i.Keys(), []string{"_.code", "page"})
+	assert(t, i.Tc("page", 2), "Seiten")
+
+	v, _ := i.Meta("listAnd")
+	assert(t, v, "und")
+	assert(t, i.Has("@@code"), false)
+
+	err = i.Set("@@code", "en")
+	assert(t, err != nil, true)
+
+	_, err = New(b)
+	assert(t, err.Error(), "missing _.name field in language file")
+
+	_, err = New([]byte(`{"_.code": "en", "_.name": "English"}`), WithMetaPrefix("@@"))
+	assert(t, err.Error(), "missing @@code field in language file")
+
+	// The prefixes of the key data follow the meta prefix.
+	d, _ := New([]byte(`{"@@code": "de", "@@name": "Deutsch", "save": "Speichern", "@@meta.save": "Button",
+		"@@src.save": "human", "@@types.greet": "name: string", "greet": "Hallo {name}", "_meta.x": "X"}`), WithMetaPrefix("@@"))
+	assert(t, d.Description("save"), "Button")
+	assert(t, d.Source("save"), "human")
+	_, err = d.TsE("greet", "name", 1)
+	assert(t, errors.Is(err, ErrParamType), true)
+	assert(t, d.Keys(), []string{"_meta.x", "greet", "save"})
+	assert(t, string(d.JSONOrdered()), `{"@@code":"de","@@name":"Deutsch","save":"Speichern","@@meta.save":"Button","@@src.save":"human","greet":"Hallo {name}","@@types.greet":"name: string","_meta.x":"X"}`)
+}
+
+func TestOptions(t *testing.T) {
+	fb, _ := New([]byte(`{"_.code": "en", "_.name": "English", "hello": "Hello {{name}}"}`), WithDelimiters("{{", "}}"))
+	i, err := New([]byte(`{"_.code": "de", "_.name": "Deutsch", "page": "Seite || Seiten", "set": "{{{{a}}}} is {{name}}", "msg": "Hi {name}"}`),
+		WithFallback(fb), WithDelimiters("{{", "}}"), WithStrict(), WithPluralSeparator("||"))
+	assert(t, err, nil)
+
+	assert(t, i.Ts("hello", "name", "Lisa"), "Hello Lisa")
+	assert(t, i.Tc("page", 2), "Seiten")
+	assert(t, i.Ts("set", "name", "x"), "{{a}} is x")
+	assert(t, i.Ts("msg", "name", "x"), "Hi {name}")
+	assert(t, i.T("set"), "{{a}} is {{name}}")
+
+	_, err = i.TsE("set")
+	assert(t, err.Error(), "set: unresolved params: name")
+
+	i, _ = New([]byte(`{"_.code": "en", "_.name": "English", "msg": "Hi %name%, 100%%"}`), WithDelimiters("%", "%"))
+	assert(t, i.Ts("msg", "name", "x"), "Hi x, 100%")
+
+	i, _ = New([]byte(`{"_.code": "en", "_.name": "English", "msg": "Hi {name}"}`), WithDelimiters("", ""))
+	assert(t, i.Ts("msg", "name", "x"), "Hi x")
+}
@@ -0,0 +1,129 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Ordinal plural rules for integers based on the CLDR ordinal plural rules.
+var (
+	ordinalEnglish = pluralRule{
+		categories: []string{"one", "two", "few", "other"},
+		index: func(n int) int {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return 0
+			case n%10 == 2 && n%100 != 12:
+				return 1
+			case n%10 == 3 && n%100 != 13:
+				return 2
+			}
+			return 3
+		},
+	}
+
+	ordinalOneOther = pluralRule{
+		categories: []string{"one", "other"},
+		index: func(n int) int {
+			if n == 1 {
+				return 0
+			}
+			return 1
+		},
+	}
+
+	ordinalItalian = pluralRule{
+		categories: []string{"many", "other"},
+		index: func(n int) int {
+			switch n {
+			case 8, 11, 80, 800:
+				return 0
+			}
+			return 1
+		},
+	}
+
+	ordinalSwedish = pluralRule{
+		categories: []string{"one", "other"},
+		index: func(n int) int {
+			if (n%10 == 1 || n%10 == 2) && n%100 != 11 && n%100 != 12 {
+				return 0
+			}
+			return 1
+		},
+	}
+)
+
+// ordinalRules maps ISO language codes to their ordinal plural rules.
+// Languages that aren't listed here have a single "other" ordinal category.
+var ordinalRules = map[string]pluralRule{
+	"en": ordinalEnglish,
+	"fr": ordinalOneOther, "ms": ordinalOneOther, "vi": ordinalOneOther,
+	"it": ordinalItalian,
+	"sv": ordinalSwedish,
+}
+
+// ordinalSuffixes are the ordinal suffixes of languages for the categories
+// of their ordinal plural rules (or of the "other" category for languages
+// without ordinal plural rules).
+var ordinalSuffixes = map[string][]string{
+	"en": {"st", "nd", "rd", "th"},
+	"fr": {"er", "e"},
+	"es": {"º"}, "it": {"º", "º"}, "pt": {"º"},
+	"de": {"."}, "da": {"."}, "nb": {"."}, "no": {"."}, "fi": {"."},
+	"cs": {"."}, "sk": {"."}, "pl": {"."}, "hu": {"."}, "tr": {"."},
+	"sl": {"."}, "hr": {"."}, "sr": {"."}, "et": {"."}, "lv": {"."},
+}
+
+// Ordinal returns the ordinal representation of n in the given language, eg:
+// 1st, 2nd, 3rd, 11th in English, 1er, 2e in French, and 1., 2. in German.
+// For languages without known ordinal suffixes, n is returned as-is.
+func Ordinal(code string, n int) string {
+	num := strconv.Itoa(n)
+
+	suffixes, ok := ordinalSuffixes[baseCode(code)]
+	if !ok {
+		return num
+	}
+
+	return num + suffixes[ordinalIndex(code, n, len(suffixes))]
+}
+
+// Tco returns the translation for the given key selecting the form for the ordinal
+// number n similar to Tc(). It expects the forms in the language string to be
+// in the order of the language's CLDR ordinal plural categories, eg:
+// `{n}st place | {n}nd place | {n}rd place | {n}th place` (one|two|few|other) in English.
+// Like Tc(), the {n} and {count} params are substituted with n, and the globals
+// (see SetGlobals()) with their values.
+func (i *I18n) Tco(key string, n int) string {
+	opt := i.subOptions()
+	s, src, ok := i.getIn(&opt.view, key)
+	if !ok {
+		return i.missing(key)
+	}
+
+	if sep, trim := src.formOptions(); strings.Contains(s, sep) {
+		chunks := strings.Split(s, sep)
+		s = trimForm(chunks[ordinalIndex(src.Code(), n, len(chunks))], trim)
+	}
+
+	return i.subParams(s, countParams(n, nil), opt)
+}
+
+// ordinalIndex returns the index of the ordinal category (form) to use for n
+// in the given language when there are the given number of forms.
+func ordinalIndex(code string, n, forms int) int {
+	if n < 0 {
+		n = -n
+	}
+
+	r, ok := ordinalRules[baseCode(code)]
+	if !ok {
+		return 0
+	}
+
+	if idx := r.index(n); idx < forms {
+		return idx
+	}
+	return forms - 1
+}
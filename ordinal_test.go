@@ -0,0 +1,26 @@
+package i18n
+
+import "testing"
+
+func TestOrdinal(t *testing.T) {
+	for n, exp := range map[int]string{
+		1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th",
+		21: "21st", 22: "22nd", 23: "23rd", 101: "101st", 111: "111th", 0: "0th",
+	} {
+		assert(t, Ordinal("en", n), exp)
+	}
+
+	assert(t, Ordinal("en-GB", 2), "2nd")
+	assert(t, Ordinal("fr", 1), "1er")
+	assert(t, Ordinal("fr", 2), "2e")
+	assert(t, Ordinal("de", 3), "3.")
+	assert(t, Ordinal("ja", 3), "3")
+
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "place": "{n}st place|{n}nd place|{n}rd place|{n}th place", "rank": "Rank"}`))
+	assert(t, i.Tco("place", 1), "1st place")
+	assert(t, i.Tco("place", 22), "22nd place")
+	assert(t, i.Tco("place", 13), "13th place")
+	assert(t, i.Tco("place", 103), "103rd place")
+	assert(t, i.Tco("rank", 2), "Rank")
+	assert(t, i.Tco("missing", 2), "missing")
+}
@@ -0,0 +1,59 @@
+package i18n
+
+// WithOverlay returns a lightweight view of the instance whose lookups check the
+// given overriding translations first and then the instance, eg: for per-tenant
+// overrides of a few strings over a shared base language. The view has the
+// instance as its fallback, so the base language map is shared and changes to it,
+// eg: Reload(), are reflected in the view. The view's settings (HTML escaping,
+// number formatting, the missing key callbacks etc.) are copied from the instance
+// at the time of creation, and its own language map only has the overrides.
+func (i *I18n) WithOverlay(overrides map[string]string) *I18n {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	o := &I18n{
+		code:          i.code,
+		name:          i.name,
+		langMap:       make(map[string]string, len(overrides)),
+		fallback:      i,
+		missingErr:    i.missingErr,
+		strict:        i.strict,
+		htmlEscape:    i.htmlEscape,
+		bidiIsolate:   i.bidiIsolate,
+		printer:       i.printer,
+		numFormat:     i.numFormat,
+		sep:           i.sep,
+		meta:          i.meta,
+		delims:        i.delims,
+		jsonc:         i.jsonc,
+		onMissing:     i.onMissing,
+		missingFmt:    i.missingFmt,
+		globals:       i.globals,
+		types:         i.types,
+		keyForms:      copyKeyForms(i.keyForms),
+		rule:          i.rule,
+		noTrim:        i.noTrim,
+		stringer:      i.stringer,
+		trimSpace:     i.trimSpace,
+		collapseSpace: i.collapseSpace,
+		emptyMissing:  i.emptyMissing,
+	}
+
+	// The language code can't be overridden, and an overriding
+	// name is the view's Name().
+	for k, v := range overrides {
+		switch k {
+		case i.metaKey("code"):
+			continue
+		case i.metaKey("name"):
+			o.name = v
+		}
+		o.langMap[k] = o.normalize(k, v)
+	}
+	o.order = sortedKeys(o.langMap)
+	if i.ciIndex != nil {
+		o.ciIndex, _ = buildCaseIndex(o.langMap)
+	}
+
+	return o
+}
@@ -0,0 +1,27 @@
+package i18n
+
+import "testing"
+
+func TestWithOverlay(t *testing.T) {
+	base, _ := New([]byte(`{"_.code": "en", "_.name": "English", "product": "Acme", "welcome": "Welcome to {product}", "support": "Email {email}", "page": "Page|Pages"}`))
+
+	o := base.WithOverlay(map[string]string{"product": "Tenant", "email": "help@tenant.com", "_.code": "fr"})
+	assert(t, o.Code(), "en")
+	assert(t, o.Name(), "English")
+	assert(t, o.T("product"), "Tenant")
+	assert(t, o.Ts("welcome", "product", "{product}"), "Welcome to Tenant")
+	assert(t, o.Ts("support", "email", "{email}"), "Email help@tenant.com")
+	assert(t, o.Tc("page", 2), "Pages")
+	assert(t, o.T("missing"), "missing")
+
+	// The base is unaffected and changes to it are reflected in the view.
+	assert(t, base.Ts("welcome", "product", "{product}"), "Welcome to Acme")
+	_ = base.Set("page", "Sheet|Sheets")
+	assert(t, o.Tc("page", 2), "Sheets")
+
+	n := base.WithOverlay(map[string]string{"_.name": "Tenant English"})
+	assert(t, n.Name(), "Tenant English")
+	v, _ := n.Meta("name")
+	assert(t, v, "Tenant English")
+	assert(t, base.Name(), "English")
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"strings"
+	"sync"
+)
+
+// Parser parses a language map in a format other than JSON, eg: YAML, into a map
+// of keys to string, number, or boolean values, or nested maps of them.
+type Parser func(b []byte) (map[string]interface{}, error)
+
+// Parsers registered with RegisterParser() by file extension.
+var (
+	parsers   = map[string]Parser{}
+	parsersMu sync.RWMutex
+)
+
+// RegisterParser registers the parser for the language map files with the given
+// extension, eg: ".yaml", that NewFromGlob() uses instead of the JSON parser. The
+// yaml and toml subpackages register their parsers when they're imported, eg:
+// import _ "github.com/knadh/go-i18n/yaml". Passing a nil p unregisters the parser.
+func RegisterParser(ext string, p Parser) {
+	ext = strings.ToLower(ext)
+
+	parsersMu.Lock()
+	if p == nil {
+		delete(parsers, ext)
+	} else {
+		parsers[ext] = p
+	}
+	parsersMu.Unlock()
+}
+
+// getParser returns the parser registered for the given file extension.
+func getParser(ext string) (Parser, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	p, ok := parsers[strings.ToLower(ext)]
+	return p, ok
+}
+
+// NewFromMap returns an I18n instance from the given language map of keys to
+// values or nested maps, eg: one returned by a Parser. Nested maps are flattened
+// into dotted keys like nested JSON objects in New(), and the map should have the
+// _.code and _.name keys.
+func NewFromMap(m map[string]interface{}, opts ...Option) (*I18n, error) {
+	l, err := flattenMap(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFromMap(l, nil, opts)
+}
+
+// LoadMap loads the given language map of keys to values or nested maps, like
+// the ones NewFromMap() accepts, into the instance overwriting existing keys
+// that conflict.
+func (i *I18n) LoadMap(m map[string]interface{}) error {
+	l, err := flattenMap(m)
+	if err != nil {
+		return err
+	}
+
+	return i.loadMap(l, nil, nil)
+}
+
+// flattenMap flattens the given map of keys to values or nested maps into
+// a flat map of dotted keys.
+func flattenMap(m map[string]interface{}) (map[string]string, error) {
+	out := make(map[string]string, len(m))
+	if err := flatten("", m, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,570 @@
+package i18n
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pluralRule represents a CLDR cardinal plural rule shared by a family of languages.
+type pluralRule struct {
+	// Plural categories in the order in which their forms are expected to
+	// appear in a pipe separated language string, eg: one|few|many.
+	categories []string
+
+	// index returns the index of the category (plural form) for n.
+	index func(n int) int
+
+	// fraction returns the index of the category for a non-integer number
+	// with the integer part i and v visible fraction digits f, eg: 1, 2, 25
+	// for 1.25. If it's nil, the last category is used.
+	fraction func(i, v, f int) int
+}
+
+// Plural rules for integers based on the CLDR plural rules.
+// https://www.unicode.org/cldr/charts/latest/supplemental/language_plural_rules.html
+var (
+	ruleOther = pluralRule{
+		categories: []string{"other"},
+		index:      func(n int) int { return 0 },
+	}
+
+	ruleOneOther = pluralRule{
+		categories: []string{"one", "other"},
+		index: func(n int) int {
+			if n == 1 {
+				return 0
+			}
+			return 1
+		},
+	}
+
+	// 0 and 1 are singular. eg: French, Portuguese.
+	ruleOneZeroOther = pluralRule{
+		categories: []string{"one", "other"},
+		index: func(n int) int {
+			if n == 0 || n == 1 {
+				return 0
+			}
+			return 1
+		},
+		fraction: func(i, v, f int) int {
+			if i == 0 || i == 1 {
+				return 0
+			}
+			return 1
+		},
+	}
+
+	// 0, 1, and the fractions below 1 are singular. Hindi, Bengali,
+	// Persian, Gujarati, Amharic, Zulu, Kannada.
+	ruleHindi = pluralRule{
+		categories: []string{"one", "other"},
+		index: func(n int) int {
+			if n == 0 || n == 1 {
+				return 0
+			}
+			return 1
+		},
+		fraction: func(i, v, f int) int {
+			if i == 0 || (i == 1 && f == 0) {
+				return 0
+			}
+			return 1
+		},
+	}
+
+	// Numbers ending in 1, except 11, are singular, as are the fractions
+	// ending in 1, eg: 21 and 0.1.
+	ruleIcelandic = pluralRule{
+		categories: []string{"one", "other"},
+		index: func(n int) int {
+			if n%10 == 1 && n%100 != 11 {
+				return 0
+			}
+			return 1
+		},
+		fraction: func(i, v, f int) int {
+			// Trailing zeros aren't considered, eg: 1.10 is 1.1.
+			for f > 0 && f%10 == 0 {
+				f /= 10
+			}
+			if f == 0 {
+				f = i
+			}
+			if f%10 == 1 && f%100 != 11 {
+				return 0
+			}
+			return 1
+		},
+	}
+
+	// Russian, Ukrainian, Belarusian.
+	ruleEastSlavic = pluralRule{
+		categories: []string{"one", "few", "many"},
+		index: func(n int) int {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return 0
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return 1
+			}
+			return 2
+		},
+
+		// CLDR's other category for fractions isn't a form, and its
+		// text is that of few, eg: 1,5 страницы.
+		fraction: func(i, v, f int) int { return 1 },
+	}
+
+	// Croatian, Serbian, Bosnian.
+	ruleSouthSlavic = pluralRule{
+		categories: []string{"one", "few", "other"},
+		index: func(n int) int {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return 0
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return 1
+			}
+			return 2
+		},
+		fraction: func(i, v, f int) int {
+			switch {
+			case f%10 == 1 && f%100 != 11:
+				return 0
+			case f%10 >= 2 && f%10 <= 4 && (f%100 < 12 || f%100 > 14):
+				return 1
+			}
+			return 2
+		},
+	}
+
+	rulePolish = pluralRule{
+		categories: []string{"one", "few", "many"},
+		index: func(n int) int {
+			switch {
+			case n == 1:
+				return 0
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return 1
+			}
+			return 2
+		},
+		fraction: func(i, v, f int) int { return 1 },
+	}
+
+	// Czech, Slovak.
+	ruleWestSlavic = pluralRule{
+		categories: []string{"one", "few", "other"},
+		index: func(n int) int {
+			switch {
+			case n == 1:
+				return 0
+			case n >= 2 && n <= 4:
+				return 1
+			}
+			return 2
+		},
+	}
+
+	ruleSlovenian = pluralRule{
+		categories: []string{"one", "two", "few", "other"},
+		index: func(n int) int {
+			switch n % 100 {
+			case 1:
+				return 0
+			case 2:
+				return 1
+			case 3, 4:
+				return 2
+			}
+			return 3
+		},
+		fraction: func(i, v, f int) int { return 2 },
+	}
+
+	ruleLithuanian = pluralRule{
+		categories: []string{"one", "few", "other"},
+		index: func(n int) int {
+			switch {
+			case n%100 >= 11 && n%100 <= 19:
+				return 2
+			case n%10 == 1:
+				return 0
+			case n%10 >= 2:
+				return 1
+			}
+			return 2
+		},
+	}
+
+	ruleLatvian = pluralRule{
+		categories: []string{"zero", "one", "other"},
+		index: func(n int) int {
+			switch {
+			case n%10 == 0 || (n%100 >= 11 && n%100 <= 19):
+				return 0
+			case n%10 == 1:
+				return 1
+			}
+			return 2
+		},
+		fraction: func(i, v, f int) int {
+			switch {
+			case v == 2 && f%100 >= 11 && f%100 <= 19:
+				return 0
+			case f%10 == 1 && (v != 2 || f%100 != 11):
+				return 1
+			}
+			return 2
+		},
+	}
+
+	ruleRomanian = pluralRule{
+		categories: []string{"one", "few", "other"},
+		index: func(n int) int {
+			switch {
+			case n == 1:
+				return 0
+			case n == 0 || (n%100 >= 2 && n%100 <= 19):
+				return 1
+			}
+			return 2
+		},
+		fraction: func(i, v, f int) int { return 1 },
+	}
+
+	ruleHebrew = pluralRule{
+		categories: []string{"one", "two", "other"},
+		index: func(n int) int {
+			switch n {
+			case 1:
+				return 0
+			case 2:
+				return 1
+			}
+			return 2
+		},
+		fraction: func(i, v, f int) int {
+			if i == 0 {
+				return 0
+			}
+			return 2
+		},
+	}
+
+	ruleIrish = pluralRule{
+		categories: []string{"one", "two", "few", "many", "other"},
+		index: func(n int) int {
+			switch {
+			case n == 1:
+				return 0
+			case n == 2:
+				return 1
+			case n >= 3 && n <= 6:
+				return 2
+			case n >= 7 && n <= 10:
+				return 3
+			}
+			return 4
+		},
+	}
+
+	ruleArabic = pluralRule{
+		categories: []string{"zero", "one", "two", "few", "many", "other"},
+		index: func(n int) int {
+			switch {
+			case n == 0:
+				return 0
+			case n == 1:
+				return 1
+			case n == 2:
+				return 2
+			case n%100 >= 3 && n%100 <= 10:
+				return 3
+			case n%100 >= 11:
+				return 4
+			}
+			return 5
+		},
+	}
+
+	ruleWelsh = pluralRule{
+		categories: []string{"zero", "one", "two", "few", "many", "other"},
+		index: func(n int) int {
+			switch n {
+			case 0:
+				return 0
+			case 1:
+				return 1
+			case 2:
+				return 2
+			case 3:
+				return 3
+			case 6:
+				return 4
+			}
+			return 5
+		},
+	}
+)
+
+// pluralRules maps ISO language codes, and the regional variants whose rules
+// differ from their base language's, to their plural rules. Languages that
+// aren't listed here use the English one|other rule with a warning.
+var pluralRules = map[string]pluralRule{
+	"en": ruleOneOther, "de": ruleOneOther, "nl": ruleOneOther, "sv": ruleOneOther,
+	"da": ruleOneOther, "no": ruleOneOther, "nb": ruleOneOther, "nn": ruleOneOther,
+	"fi": ruleOneOther, "et": ruleOneOther, "es": ruleOneOther, "it": ruleOneOther,
+	"pt-pt": ruleOneOther, "el": ruleOneOther, "hu": ruleOneOther, "tr": ruleOneOther,
+	"bg": ruleOneOther, "ca": ruleOneOther, "eu": ruleOneOther, "gl": ruleOneOther,
+	"af": ruleOneOther, "sq": ruleOneOther, "az": ruleOneOther, "ka": ruleOneOther,
+	"kk": ruleOneOther, "ky": ruleOneOther, "mn": ruleOneOther, "ne": ruleOneOther,
+	"ta": ruleOneOther, "te": ruleOneOther, "ml": ruleOneOther,
+	"mr": ruleOneOther, "ur": ruleOneOther, "sw": ruleOneOther, "uz": ruleOneOther,
+	"tk": ruleOneOther, "fy": ruleOneOther, "lb": ruleOneOther,
+
+	"ja": ruleOther, "zh": ruleOther, "ko": ruleOther, "vi": ruleOther,
+	"th": ruleOther, "id": ruleOther, "ms": ruleOther, "lo": ruleOther,
+	"my": ruleOther, "km": ruleOther,
+
+	"fr": ruleOneZeroOther, "hy": ruleOneZeroOther, "kab": ruleOneZeroOther,
+	"pt": ruleOneZeroOther,
+
+	"hi": ruleHindi, "bn": ruleHindi, "fa": ruleHindi, "gu": ruleHindi,
+	"am": ruleHindi, "zu": ruleHindi, "kn": ruleHindi,
+
+	"is": ruleIcelandic,
+
+	"ru": ruleEastSlavic, "uk": ruleEastSlavic, "be": ruleEastSlavic,
+	"hr": ruleSouthSlavic, "sr": ruleSouthSlavic, "bs": ruleSouthSlavic,
+	"pl": rulePolish,
+	"cs": ruleWestSlavic, "sk": ruleWestSlavic,
+	"sl": ruleSlovenian,
+	"lt": ruleLithuanian,
+	"lv": ruleLatvian,
+	"ro": ruleRomanian, "mo": ruleRomanian,
+	"he": ruleHebrew, "iw": ruleHebrew,
+	"ga": ruleIrish,
+	"ar": ruleArabic,
+	"cy": ruleWelsh,
+}
+
+// Custom plural functions registered with RegisterPluralFunc().
+var (
+	pluralFuncs   = map[string]func(n int) int{}
+	pluralFuncsMu sync.RWMutex
+)
+
+// RegisterPluralFunc registers a custom plural function for the given language
+// code that returns the index of the pipe separated form Tc() should use for n.
+// A registered function takes precedence over the built-in plural rules for all
+// instances with the code. Passing a nil fn unregisters the function.
+func RegisterPluralFunc(code string, fn func(n int) int) {
+	code = strings.ToLower(code)
+
+	pluralFuncsMu.Lock()
+	if fn == nil {
+		delete(pluralFuncs, code)
+	} else {
+		pluralFuncs[code] = fn
+	}
+	pluralFuncsMu.Unlock()
+}
+
+// getPluralFunc returns the custom plural function registered for
+// the given language code or its base language (pt-BR => pt).
+func getPluralFunc(code string) (func(n int) int, bool) {
+	code = strings.ToLower(code)
+
+	pluralFuncsMu.RLock()
+	defer pluralFuncsMu.RUnlock()
+
+	if fn, ok := pluralFuncs[code]; ok {
+		return fn, true
+	}
+
+	fn, ok := pluralFuncs[baseCode(code)]
+	return fn, ok
+}
+
+// getPluralRule returns the plural rule for the given language code.
+// Region suffixes are ignored, eg: pt-BR => pt, unless the variant has
+// its own rule, eg: pt-PT.
+func getPluralRule(code string) pluralRule {
+	if r, ok := lookupPluralRule(code); ok {
+		return r
+	}
+
+	return ruleOneOther
+}
+
+// lookupPluralRule returns the plural rule for the given language code,
+// or its base language, if there's one.
+func lookupPluralRule(code string) (pluralRule, bool) {
+	if r, ok := pluralRules[strings.ReplaceAll(strings.ToLower(code), "_", "-")]; ok {
+		return r, true
+	}
+
+	r, ok := pluralRules[baseCode(code)]
+	return r, ok
+}
+
+// ruleName returns the name of the plural rule family for the given language
+// code, which is its categories separated by /, eg: one/few/many for Russian.
+func ruleName(code string) string {
+	return strings.Join(getPluralRule(code).categories, "/")
+}
+
+// pluralWarning returns a warning if there are no plural rules for the given
+// language code, or a custom plural function registered for it.
+func pluralWarning(code string) string {
+	if _, ok := lookupPluralRule(code); ok {
+		return ""
+	}
+	if _, ok := getPluralFunc(code); ok {
+		return ""
+	}
+
+	return "unknown plural rules for " + code + ", using one/other"
+}
+
+// PluralRule returns the name of the CLDR plural rule family of the instance's
+// language, which is its plural categories in the order of the forms, eg:
+// one/other for English and one/few/many for Russian, or custom if a plural
+// function is registered for the language with RegisterPluralFunc(). Unknown
+// languages use one/other, with a warning in Warnings().
+func (i *I18n) PluralRule() string {
+	i.mu.RLock()
+	code, rule := i.code, i.rule
+	i.mu.RUnlock()
+
+	if _, ok := getPluralFunc(code); ok {
+		return "custom"
+	}
+
+	return rule
+}
+
+// PluralForms returns the number of CLDR plural categories (forms) for integers
+// in the instance's language, eg: 2 (one|other) for English and 3 (one|few|many)
+// for Russian. Languages without plurals, eg: Japanese, return 1 and unknown
+// languages return 2.
+func (i *I18n) PluralForms() int {
+	return len(getPluralRule(i.Code()).categories)
+}
+
+// baseCode returns the lowercased base language of a language code, eg: pt-BR => pt.
+func baseCode(code string) string {
+	code = strings.ToLower(code)
+	if n := strings.IndexAny(code, "-_"); n > 0 {
+		return code[:n]
+	}
+
+	return code
+}
+
+// pluralIndex returns the index of the form to use for n in a pipe separated
+// language string with the given number of forms.
+//
+//   - A custom plural function registered for the language is always used.
+//   - Two forms are always Singular|Plural, where Singular is used for 1 (or for
+//     the CLDR "one" category in languages that have it, eg: 0 and 1 in French).
+//   - With one form more than the language's plural categories, the first one
+//     is a zero form, eg: `zero|one|other` in English.
+//   - Otherwise, the forms are in the order of the language's CLDR categories.
+func pluralIndex(code string, n, forms int) int {
+	var idx int
+	if fn, ok := getPluralFunc(code); ok {
+		idx = fn(n)
+	} else {
+		if n < 0 {
+			n = -n
+		}
+
+		// Languages without a singular/plural distinction (eg: Japanese),
+		// and Singular|Plural strings in languages with more categories
+		// use the English one|other rule.
+		r := getPluralRule(code)
+		if len(r.categories) == 1 || (forms == 2 && len(r.categories) != 2) {
+			r = ruleOneOther
+		}
+
+		if forms > 2 && forms == len(r.categories)+1 {
+			if n == 0 {
+				return 0
+			}
+			idx = 1 + r.index(n)
+		} else {
+			idx = r.index(n)
+		}
+	}
+
+	if idx < 0 {
+		return 0
+	} else if idx >= forms {
+		return forms - 1
+	}
+
+	return idx
+}
+
+// pluralIndexFloat is pluralIndex() for a float n. Integral values, eg: 1.0, are
+// treated as integers, and the form for non-integer values is selected with the
+// CLDR rules for fractions (see Tcf()).
+func pluralIndexFloat(code string, n float64, forms int) int {
+	n = math.Abs(n)
+	if n == math.Trunc(n) && n <= math.MaxInt32 {
+		return pluralIndex(code, int(n), forms)
+	}
+
+	r := getPluralRule(code)
+	if len(r.categories) == 1 || (forms == 2 && len(r.categories) != 2) {
+		r = ruleOneOther
+	}
+
+	idx := r.fractionIndex(n)
+	if forms > 2 && forms == len(r.categories)+1 {
+		idx++
+	}
+
+	if idx >= forms {
+		return forms - 1
+	}
+
+	return idx
+}
+
+// fractionIndex returns the index of the category for the non-integer n.
+func (r pluralRule) fractionIndex(n float64) int {
+	if r.fraction == nil {
+		return len(r.categories) - 1
+	}
+
+	// The CLDR operands i, v, and f are derived from the shortest
+	// decimal representation of n, eg: 1.50 is 1.5.
+	s := strconv.FormatFloat(n, 'f', -1, 64)
+	in, frac, _ := strings.Cut(s, ".")
+	i, _ := strconv.Atoi(in)
+
+	// Only the last digits of f matter to the rules.
+	v := len(frac)
+	if v > 9 {
+		frac = frac[v-9:]
+	}
+	f, _ := strconv.Atoi(frac)
+
+	return r.fraction(i, v, f)
+}
+
+// pluralCategory returns the index of the plural category (form) in a pipe
+// separated language string that should be used for n in the given language.
+func pluralCategory(code string, n int) int {
+	if n < 0 {
+		n = -n
+	}
+
+	return getPluralRule(code).index(n)
+}
@@ -0,0 +1,30 @@
+package i18n
+
+// Scoped is a lightweight translator that prefixes the keys passed to it with
+// a namespace before looking them up in its parent I18n instance. It shares the
+// parent's language map and reflects any changes to it.
+type Scoped struct {
+	i      *I18n
+	prefix string
+}
+
+// Scope returns a Scoped translator for the keys under the given prefix.
+// eg: i.Scope("admin.campaigns").T("form.title") = i.T("admin.campaigns.form.title")
+func (i *I18n) Scope(prefix string) *Scoped {
+	return &Scoped{i: i, prefix: prefix + "."}
+}
+
+// T is I18n.T() for the scoped key.
+func (s *Scoped) T(key string) string {
+	return s.i.T(s.prefix + key)
+}
+
+// Ts is I18n.Ts() for the scoped key.
+func (s *Scoped) Ts(key string, params ...interface{}) string {
+	return s.i.Ts(s.prefix+key, params...)
+}
+
+// Tc is I18n.Tc() for the scoped key.
+func (s *Scoped) Tc(key string, n int) string {
+	return s.i.Tc(s.prefix+key, n)
+}
@@ -0,0 +1,17 @@
+package i18n
+
+import "testing"
+
+func TestScope(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "admin.campaigns.form.title": "New {name}", "admin.campaigns.count": "Campaign|Campaigns"}`))
+
+	s := i.Scope("admin.campaigns")
+	assert(t, s.T("form.title"), "New {name}")
+	assert(t, s.Ts("form.title", "name", "campaign"), "New campaign")
+	assert(t, s.Tc("count", 2), "Campaigns")
+
+	if err := i.Load([]byte(`{"admin.campaigns.form.title": "Create {name}"}`)); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, s.Ts("form.title", "name", "campaign"), "Create campaign")
+}
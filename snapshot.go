@@ -0,0 +1,165 @@
+package i18n
+
+import "golang.org/x/text/message"
+
+// Snapshot is a point-in-time copy of an instance's language map that can
+// be restored with Restore(). See Snapshot().
+type Snapshot struct {
+	langMap map[string]string
+	order   []string
+	code    string
+	name    string
+	rule    string
+	printer *message.Printer
+	descs   map[string]string
+	srcs    map[string]string
+	types   map[string]map[string]string
+	cands   map[string][]Candidate
+}
+
+// Snapshot returns a copy of the current language map and code, along with the
+// descriptions, sources, param types, and candidates of the keys, that can be
+// restored with Restore(), eg: to temporarily override strings with Set() and
+// roll back.
+func (i *I18n) Snapshot() Snapshot {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return Snapshot{
+		langMap: copyMap(i.langMap),
+		order:   append([]string(nil), i.order...),
+		code:    i.code,
+		name:    i.name,
+		rule:    i.rule,
+		printer: i.printer,
+		descs:   copyMapOrNil(i.descs),
+		srcs:    copyMapOrNil(i.srcs),
+		types:   i.types,
+		cands:   copyCands(i.cands),
+	}
+}
+
+// Restore replaces the language map and code, and the descriptions, sources,
+// param types, and candidates of the keys, with the ones in the given snapshot
+// taken from the instance, discarding all changes made after it was taken. A
+// snapshot can be restored any number of times.
+func (i *I18n) Restore(s Snapshot) {
+	if s.langMap == nil {
+		return
+	}
+
+	i.mu.Lock()
+	i.langMap = copyMap(s.langMap)
+	i.order = append([]string(nil), s.order...)
+	i.code = s.code
+	i.name = s.name
+	i.rule = s.rule
+	i.printer = s.printer
+	i.descs = copyMapOrNil(s.descs)
+	i.srcs = copyMapOrNil(s.srcs)
+	i.types = s.types
+	i.cands = copyCands(s.cands)
+	if i.ciIndex != nil {
+		i.ciIndex, _ = buildCaseIndex(i.langMap)
+	}
+	i.mu.Unlock()
+	i.resetPluralCache()
+}
+
+// Clone returns a deep copy of the instance with its own copy of the language map
+// and the settings, so that changes to the clone, eg: Set(), don't affect the
+// instance and vice versa. The fallback instance and the callbacks are shared.
+func (i *I18n) Clone() *I18n {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	c := &I18n{
+		code:          i.code,
+		name:          i.name,
+		langMap:       copyMap(i.langMap),
+		order:         append([]string(nil), i.order...),
+		fallback:      i.fallback,
+		missingErr:    i.missingErr,
+		strict:        i.strict,
+		htmlEscape:    i.htmlEscape,
+		bidiIsolate:   i.bidiIsolate,
+		printer:       i.printer,
+		numFormat:     i.numFormat,
+		sep:           i.sep,
+		meta:          i.meta,
+		delims:        i.delims,
+		jsonc:         i.jsonc,
+		path:          i.path,
+		fsys:          i.fsys,
+		onReload:      i.onReload,
+		onMissing:     i.onMissing,
+		missingFmt:    i.missingFmt,
+		globals:       i.globals,
+		types:         i.types,
+		keyForms:      copyKeyForms(i.keyForms),
+		rule:          i.rule,
+		noTrim:        i.noTrim,
+		stringer:      i.stringer,
+		resolver:      i.resolver,
+		trimSpace:     i.trimSpace,
+		collapseSpace: i.collapseSpace,
+		emptyMissing:  i.emptyMissing,
+	}
+	if i.ciIndex != nil {
+		c.ciIndex = copyMap(i.ciIndex)
+	}
+	c.warnings = append([]string(nil), i.warnings...)
+	c.descs = copyMapOrNil(i.descs)
+	c.srcs = copyMapOrNil(i.srcs)
+	c.cands = copyCands(i.cands)
+
+	return c
+}
+
+// copyCands returns a copy of the candidates of keys. The slices of candidates
+// are shared as they're replaced and not modified.
+func copyCands(m map[string][]Candidate) map[string][]Candidate {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string][]Candidate, len(m))
+	for k, c := range m {
+		out[k] = c
+	}
+
+	return out
+}
+
+// copyKeyForms returns a copy of the declared number of plural forms of keys.
+func copyKeyForms(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]int, len(m))
+	for k, n := range m {
+		out[k] = n
+	}
+
+	return out
+}
+
+// copyMapOrNil returns a copy of the given map, or nil if it's nil.
+func copyMapOrNil(l map[string]string) map[string]string {
+	if l == nil {
+		return nil
+	}
+
+	return copyMap(l)
+}
+
+// copyMap returns a copy of a language map.
+func copyMap(l map[string]string) map[string]string {
+	out := make(map[string]string, len(l))
+	for k, v := range l {
+		out[k] = v
+	}
+
+	return out
+}
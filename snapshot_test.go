@@ -0,0 +1,82 @@
+package i18n
+
+import "testing"
+
+func TestSnapshot(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar"}`))
+
+	s := i.Snapshot()
+	_ = i.Set("foo", "Tenant foo")
+	_ = i.Set("_.name", "Tenant English")
+	_ = i.Set("new", "New")
+	_ = i.Delete("bar")
+	assert(t, i.T("foo"), "Tenant foo")
+
+	i.Restore(s)
+	assert(t, i.T("foo"), "Foo")
+	assert(t, i.T("bar"), "Bar")
+	assert(t, i.Has("new"), false)
+	assert(t, i.Name(), "English")
+	assert(t, string(i.JSONOrdered()), `{"_.code":"en","_.name":"English","foo":"Foo","bar":"Bar"}`)
+
+	// Snapshots can be restored repeatedly.
+	_ = i.Set("foo", "Again")
+	i.Restore(s)
+	assert(t, i.T("foo"), "Foo")
+}
+
+func TestSnapshotCode(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "page": "Page|Pages"}`))
+	s := i.Snapshot()
+
+	i.SetCode("fr")
+	assert(t, i.Tc("page", 0), "Page")
+
+	i.Restore(s)
+	assert(t, i.Code(), "en")
+	v, _ := i.Meta("code")
+	assert(t, v, "en")
+	assert(t, i.Tc("page", 0), "Pages")
+	_, p := i.locale()
+	assert(t, p == s.printer, true)
+}
+
+func TestSnapshotKeyData(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English",
+		"save": ["Save", "Store"], "_meta.save": "Button label",
+		"greet": "Hi {name}", "_src.greet": "deepl", "_types.greet": "name: string"}`))
+
+	s := i.Snapshot()
+	assert(t, i.SetWinner("save", 1), nil)
+	_ = i.Load([]byte(`{"_meta.save": "Updated", "_src.greet": "human", "_types.greet": "name: int"}`))
+	_ = i.Delete("greet")
+
+	i.Restore(s)
+	assert(t, i.T("save"), "Save")
+	assert(t, i.Candidates("save"), []Candidate{{"Save", "machine", true}, {"Store", "machine", false}})
+	assert(t, i.Source("save"), "machine")
+	assert(t, i.Description("save"), "Button label")
+	assert(t, i.Source("greet"), "deepl")
+	_, err := i.TsE("greet", "name", "John")
+	assert(t, err, nil)
+}
+
+func TestClone(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "foo": "Foo", "bar": "Bar"}`))
+	i.SetHTMLEscape(true)
+
+	c := i.Clone()
+	_ = c.Set("foo", "Clone foo")
+	_ = c.Delete("bar")
+	c.SetName("Clone")
+	_ = i.Set("baz", "Baz")
+
+	assert(t, i.T("foo"), "Foo")
+	assert(t, i.T("bar"), "Bar")
+	assert(t, i.Name(), "English")
+	assert(t, c.T("foo"), "Clone foo")
+	assert(t, c.Has("bar"), false)
+	assert(t, c.Has("baz"), false)
+	assert(t, c.Name(), "Clone")
+	assert(t, c.htmlEscape, true)
+}
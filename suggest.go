@@ -0,0 +1,76 @@
+package i18n
+
+import "strings"
+
+// Suggest returns the key in the instance's language map that's the closest
+// to the given (missing) key by the edit distance, eg: pageTitle for pageTitel,
+// for helpful error messages about typos in keys. It can be called from the
+// OnMissing() callback. An empty string is returned if there's no key that's
+// close enough, ie: within a third of the key's length (at least one edit).
+func (i *I18n) Suggest(key string) string {
+	var (
+		lk    = []rune(strings.ToLower(key))
+		limit = len(lk)/3 + 1
+		best  string
+	)
+	for _, k := range i.Keys() {
+		if k == key {
+			continue
+		}
+
+		if d := editDistance(lk, []rune(strings.ToLower(k)), limit); d <= limit {
+			best, limit = k, d-1
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b, or a value
+// greater than limit if the distance exceeds limit.
+func editDistance(a, b []rune, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for n := range prev {
+		prev[n] = n
+	}
+
+	for x := 1; x <= len(a); x++ {
+		cur[0] = x
+		low := cur[0]
+		for y := 1; y <= len(b); y++ {
+			cost := 1
+			if a[x-1] == b[y-1] {
+				cost = 0
+			}
+
+			cur[y] = minInt(prev[y]+1, cur[y-1]+1, prev[y-1]+cost)
+			if cur[y] < low {
+				low = cur[y]
+			}
+		}
+
+		// No alignment can get under limit anymore.
+		if low > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+
+	return a
+}
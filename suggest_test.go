@@ -0,0 +1,21 @@
+package i18n
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pageTitle": "Page", "pageTitles": "Pages", "save": "Save", "globals.terms.name": "Name"}`))
+
+	assert(t, i.Suggest("pageTitel"), "pageTitle")
+	assert(t, i.Suggest("PAGETITLE"), "pageTitle")
+	assert(t, i.Suggest("sav"), "save")
+	assert(t, i.Suggest("globals.term.name"), "globals.terms.name")
+	assert(t, i.Suggest("somethingElse"), "")
+	assert(t, i.Suggest("x"), "")
+
+	var got string
+	i.OnMissing(func(key, code string) {
+		got = i.Suggest(key)
+	})
+	i.T("pageTitel")
+	assert(t, got, "pageTitle")
+}
@@ -0,0 +1,14 @@
+package i18n
+
+import "text/template"
+
+// FuncMap returns a template.FuncMap with the instance's translation functions
+// that can be used in text/template and html/template templates.
+// eg: {{ t "pageTitle" }}, {{ ts "pageVars" "name" .Name }}, {{ tc "page" .Count }}
+func (i *I18n) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"t":  i.T,
+		"ts": i.Ts,
+		"tc": i.Tc,
+	}
+}
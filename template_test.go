@@ -0,0 +1,26 @@
+package i18n
+
+import (
+	"bytes"
+	htmltpl "html/template"
+	"testing"
+	"text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English", "pageTitle": "Welcome", "pageVars": "The page is named {name}", "page": "Page|Pages"}`))
+
+	tpl := `{{ t "pageTitle" }}. {{ ts "pageVars" "name" .Name }}. {{ tc "page" .Count }}.`
+
+	var b bytes.Buffer
+	if err := template.Must(template.New("").Funcs(i.FuncMap()).Parse(tpl)).Execute(&b, map[string]interface{}{"Name": "<Foo>", "Count": 2}); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, b.String(), "Welcome. The page is named <Foo>. Pages.")
+
+	b.Reset()
+	if err := htmltpl.Must(htmltpl.New("").Funcs(i.FuncMap()).Parse(tpl)).Execute(&b, map[string]interface{}{"Name": "<Foo>", "Count": 1}); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, b.String(), "Welcome. The page is named &lt;Foo&gt;. Page.")
+}
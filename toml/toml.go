@@ -0,0 +1,45 @@
+// Package toml adds TOML language maps to i18n. Importing it registers its parser
+// for the .toml files in i18n.NewFromGlob().
+package toml
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/knadh/go-i18n"
+)
+
+func init() {
+	i18n.RegisterParser(".toml", Parse)
+}
+
+// New returns an I18n instance from the given TOML language map bytes.
+// Nested tables are flattened into dotted keys like nested JSON objects in
+// i18n.New(), and the map should have the _.code and _.name keys.
+func New(b []byte, opts ...i18n.Option) (*i18n.I18n, error) {
+	m, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return i18n.NewFromMap(m, opts...)
+}
+
+// Load loads a TOML language map into the instance overwriting
+// existing keys that conflict.
+func Load(i *i18n.I18n, b []byte) error {
+	m, err := Parse(b)
+	if err != nil {
+		return err
+	}
+
+	return i.LoadMap(m)
+}
+
+// Parse parses a TOML language map. It's an i18n.Parser.
+func Parse(b []byte) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := toml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,72 @@
+package toml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/knadh/go-i18n"
+)
+
+func assert(t *testing.T, a, v interface{}) {
+	t.Helper()
+	if fmt.Sprintf("%v", a) != fmt.Sprintf("%v", v) {
+		t.Fatalf("expected '%v', got '%v'", a, v)
+	}
+}
+
+func TestTOML(t *testing.T) {
+	b := `
+"_.code" = "en"
+"_.name" = "English"
+
+pageTitle = "Welcome to the page"
+page = "Single page|Many pages"
+
+[globals.message]
+notFound = "{name} not found"
+`
+
+	i, err := New([]byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.Code(), "en")
+	assert(t, i.T("pageTitle"), "Welcome to the page")
+	assert(t, i.Ts("globals.message.notFound", "name", "Page"), "Page not found")
+	assert(t, i.Tc("page", 2), "Many pages")
+
+	if err := Load(i, []byte("[admin]\nfoo = \"Foo\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("admin.foo"), "Foo")
+
+	if _, err := New([]byte("foo = \"Foo\"\n")); err == nil {
+		t.Fatal("expected error for missing meta keys")
+	}
+	if err := Load(i, []byte("foo = [\"a\"]\n")); err == nil {
+		t.Fatal("expected error for array value")
+	}
+}
+
+func TestNewFromGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json": `{"_.code": "en", "_.name": "English", "foo": "Foo"}`,
+		"b.toml": "[admin]\ntitle = \"Admin\"\n",
+	}
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(b), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	i, err := i18n.NewFromGlob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("foo"), "Foo")
+	assert(t, i.T("admin.title"), "Admin")
+}
@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrParamType is returned by TsE() when a param value's type doesn't match
+// the type declared for it in the _types.* keys.
+var ErrParamType = errors.New("invalid param type")
+
+// paramTypes are the types that can be declared for params and the functions
+// that check whether a value is of the type.
+var paramTypes = map[string]func(v interface{}) bool{
+	"string": func(v interface{}) bool {
+		_, ok := v.(string)
+		return ok
+	},
+	"number": func(v interface{}) bool {
+		switch v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+			float32, float64, json.Number, PercentValue:
+			return true
+		}
+		return false
+	},
+	"int": func(v interface{}) bool {
+		switch v := v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		case json.Number:
+			_, err := v.Int64()
+			return err == nil
+		}
+		return false
+	},
+	"bool": func(v interface{}) bool {
+		_, ok := v.(bool)
+		return ok
+	},
+	"time": func(v interface{}) bool {
+		_, ok := v.(time.Time)
+		return ok
+	},
+	"money": func(v interface{}) bool {
+		_, ok := v.(MoneyValue)
+		return ok
+	},
+}
+
+// parseTypes parses the param type declarations in the _types.* keys, eg:
+// "_types.pageVars": "name: string, count: number", into key => param => type
+// maps and returns warnings for invalid declarations, which are skipped.
+func parseTypes(decls map[string]string) (map[string]map[string]string, []string) {
+	if len(decls) == 0 {
+		return nil, nil
+	}
+
+	var (
+		out   = make(map[string]map[string]string, len(decls))
+		warns []string
+	)
+	for key, d := range decls {
+		m := make(map[string]string)
+		for _, p := range strings.Split(d, ",") {
+			name, typ, ok := strings.Cut(p, ":")
+			name, typ = strings.TrimSpace(name), strings.TrimSpace(typ)
+			if !ok || !isParamName(name) {
+				warns = append(warns, fmt.Sprintf("invalid param type declaration for %s: %q", key, strings.TrimSpace(p)))
+				continue
+			}
+			if _, ok := paramTypes[typ]; !ok {
+				warns = append(warns, fmt.Sprintf("unknown param type for %s: %s: %s", key, name, typ))
+				continue
+			}
+
+			m[name] = typ
+		}
+
+		if len(m) > 0 {
+			out[key] = m
+		}
+	}
+	sort.Strings(warns)
+
+	return out, warns
+}
+
+// formatTypes returns the _types.* declaration for the param types of a key.
+func formatTypes(m map[string]string) string {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for n, name := range names {
+		names[n] = name + ": " + m[name]
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// checkParamTypes returns an ErrParamType error if the values of any of the
+// params have a type other than the one declared for them for the key in the
+// instance, or if it has no declarations for the key, in src.
+func (i *I18n) checkParamTypes(key string, src *I18n, params []interface{}) error {
+	i.mu.RLock()
+	types, ok := i.types[key]
+	i.mu.RUnlock()
+
+	if !ok && src != nil && src != i {
+		src.mu.RLock()
+		types, ok = src.types[key]
+		src.mu.RUnlock()
+	}
+	if !ok {
+		return nil
+	}
+
+	var bad []string
+	for n := 0; n+1 < len(params); n += 2 {
+		name := toString(params[n])
+		typ, ok := types[name]
+		if !ok || paramTypes[typ](params[n+1]) {
+			continue
+		}
+
+		bad = append(bad, fmt.Sprintf("%s: expected %s, got %T", name, typ, params[n+1]))
+	}
+	if len(bad) > 0 {
+		return fmt.Errorf("%s: %w: %s", key, ErrParamType, strings.Join(bad, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate checks the language map for structural mistakes and returns the list of
+// errors found, sorted by key. It reports plural strings with a number of pipe
+// separated forms that's neither Singular|Plural, nor the number of plural
+// categories of the language (or one more, with a zero form). If a reference
+// instance (eg: the base language) is given, keys whose {params} differ from
+// the ones in the reference are reported too.
+func (i *I18n) Validate(ref *I18n) []error {
+	var (
+		errs     []error
+		cats     = i.PluralForms()
+		_, isFn  = getPluralFunc(i.Code())
+		sep      = i.pluralSep()
+		refMap   map[string]string
+		keyForms = i.declaredForms()
+		keys     = i.Keys()
+		langMap  = i.Raw()
+	)
+	if ref != nil {
+		refMap = ref.Raw()
+	}
+
+	for _, k := range keys {
+		v := langMap[k]
+
+		// Plural forms.
+		if !isFn && strings.Contains(v, sep) && !isSelect(v, sep) {
+			n := len(parsePlural(v, sep, true).forms)
+			if d, ok := keyForms[k]; ok {
+				if n != d {
+					errs = append(errs, fmt.Errorf("%s: %d plural forms, expected %d", k, n, d))
+				}
+			} else if n != 1 && n != 2 && n != cats && n != cats+1 {
+				errs = append(errs, fmt.Errorf("%s: %d plural forms, expected 2, %d, or %d", k, n, cats, cats+1))
+			}
+		}
+
+		// Placeholders.
+		if refMap == nil {
+			continue
+		}
+		rv, ok := refMap[k]
+		if !ok {
+			continue
+		}
+
+		if a, b := getPlaceholders(v, i.delims), getPlaceholders(rv, ref.delims); !sameStrings(a, b) {
+			errs = append(errs, fmt.Errorf("%s: params {%s} differ from {%s} in %s", k,
+				strings.Join(a, "}, {"), strings.Join(b, "}, {"), ref.Code()))
+		}
+	}
+
+	return errs
+}
+
+// declaredForms returns a copy of the keys' declared number of plural forms.
+func (i *I18n) declaredForms() map[string]int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return copyKeyForms(i.keyForms)
+}
+
+// CheckPlaceholders compares the {params} in the translations in the instance
+// with those in the translations of the same keys in the reference instance (eg:
+// the base language), and returns the params that are in the reference but are
+// missing in the instance, and the extra params that are in the instance but
+// not in the reference. eg: {"hello": ["name"]} and {"hello": ["nom"]} if the
+// reference has "Hello {name}" and the instance has "Bonjour {nom}". Keys that
+// are missing in either instance are not considered.
+func (i *I18n) CheckPlaceholders(ref *I18n) (missing map[string][]string, extra map[string][]string) {
+	missing, extra = map[string][]string{}, map[string][]string{}
+
+	langMap := i.Raw()
+	for k, rv := range ref.Raw() {
+		if strings.HasPrefix(k, i.meta) {
+			continue
+		}
+
+		v, ok := langMap[k]
+		if !ok {
+			continue
+		}
+
+		if d := diffPlaceholders(getPlaceholders(rv, ref.delims), getPlaceholders(v, i.delims)); d != nil {
+			missing[k] = d
+		}
+		if d := diffPlaceholders(getPlaceholders(v, i.delims), getPlaceholders(rv, ref.delims)); d != nil {
+			extra[k] = d
+		}
+	}
+
+	return missing, extra
+}
+
+// diffPlaceholders returns the params in a that are not in b.
+func diffPlaceholders(a, b []string) []string {
+	has := make(map[string]bool, len(b))
+	for _, p := range b {
+		has[p] = true
+	}
+
+	var out []string
+	for _, p := range a {
+		if !has[p] {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+// isSelect returns true if all the forms in s separated by sep are
+// labelled TSelect() branches, eg: male: He | female: She.
+func isSelect(s, sep string) bool {
+	for _, c := range strings.Split(s, sep) {
+		label, _, ok := strings.Cut(c, ":")
+		if !ok || !isParamName(strings.TrimSpace(label)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Placeholders returns the distinct {param} names in s in the order in which
+// they appear, eg: [name count] for "Hi {name}, {count} new {{literal}}", as
+// detected by Ts() and the other substitution functions and by Validate(), eg:
+// for linting the placeholders of translations against those of the source
+// strings. Escaped braces, and braces with text that isn't a param name, eg:
+// { name } or a JSON snippet, are ignored. Use the instance's Placeholders() for
+// instances with custom delimiters (see WithDelimiters()).
+func Placeholders(s string) []string {
+	return getPlaceholders(s, defaultDelims)
+}
+
+// Placeholders is Placeholders() with the instance's param delimiters.
+func (i *I18n) Placeholders(s string) []string {
+	return getPlaceholders(s, i.delims)
+}
+
+// getPlaceholders returns the distinct {param} names with the given delimiters
+// in s in the order in which they appear. Escaped delimiters are ignored.
+func getPlaceholders(s string, d delims) []string {
+	var (
+		out  []string
+		seen = map[string]bool{}
+	)
+	d.replaceParams(s, func(name string) (string, bool) {
+		if isParamName(name) && !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+		return "", false
+	})
+
+	return out
+}
+
+// sameStrings returns true if a and b have the same set of strings.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	m := make(map[string]bool, len(a))
+	for _, v := range a {
+		m[v] = true
+	}
+	for _, v := range b {
+		if !m[v] {
+			return false
+		}
+	}
+
+	return true
+}
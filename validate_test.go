@@ -0,0 +1,48 @@
+package i18n
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English",
+		"page": "Page|Pages",
+		"items": "{count} item|{count} items",
+		"hello": "Hello {name}",
+		"welcome": "Welcome {name} {{literal}}"}`))
+
+	ru, _ := New([]byte(`{"_.code": "ru", "_.name": "Russian",
+		"_.months": "a|b|c|d|e|f|g|h|i|j|k|l",
+		"page": "a|b|c|d|e",
+		"items": "{count} a|{count} b|{count} c",
+		"hello": "Привет {nom}",
+		"welcome": "Привет {name}",
+		"gender": "male: a | female: b | other: c"}`))
+
+	assert(t, en.Validate(nil), []error(nil))
+
+	errs := ru.Validate(en)
+	assert(t, len(errs), 2)
+	assert(t, errs[0], "hello: params {nom} differ from {name} in en")
+	assert(t, errs[1], "page: 5 plural forms, expected 2, 3, or 4")
+}
+
+func TestPlaceholders(t *testing.T) {
+	assert(t, Placeholders("Hi {name}, {count} new {name} {{literal}} { spaced } {a_b} {x.y-z}"), []string{"name", "count", "x.y-z"})
+	assert(t, Placeholders("{summary:5} {\"json\": 1} {}"), []string(nil))
+	assert(t, Placeholders("No params"), []string(nil))
+
+	i, _ := New([]byte(`{"_.code": "en", "_.name": "English"}`), WithDelimiters("{{", "}}"))
+	assert(t, i.Placeholders("Hi {{name}} {count}"), []string{"name"})
+}
+
+func TestCheckPlaceholders(t *testing.T) {
+	en, _ := New([]byte(`{"_.code": "en", "_.name": "English", "hello": "Hello {name} {title}", "bye": "Bye {name}", "ok": "OK {name}", "only": "{x}"}`))
+	fr, _ := New([]byte(`{"_.code": "fr", "_.name": "French", "hello": "Bonjour {nom}", "bye": "Au revoir {name} {extra}", "ok": "OK {name}"}`))
+
+	missing, extra := fr.CheckPlaceholders(en)
+	assert(t, missing, map[string][]string{"hello": {"name", "title"}})
+	assert(t, extra, map[string][]string{"hello": {"nom"}, "bye": {"extra"}})
+
+	missing, extra = en.CheckPlaceholders(fr)
+	assert(t, missing, map[string][]string{"hello": {"nom"}, "bye": {"extra"}})
+	assert(t, extra, map[string][]string{"hello": {"name", "title"}})
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is the duration for which consecutive changes to a watched
+// file are collapsed into a single reload.
+const watchDebounce = 100 * time.Millisecond
+
+// OnReload sets an optional callback that's invoked with the result of
+// every reload triggered by Watch().
+func (i *I18n) OnReload(fn func(err error)) {
+	i.mu.Lock()
+	i.onReload = fn
+	i.mu.Unlock()
+}
+
+// Watch starts watching the file the instance was created from with
+// NewFromFile() and automatically reloads it (see Reload()) when it changes.
+// Rapid consecutive writes, eg: from a single editor save, trigger only one
+// reload. Watching stops when ctx is cancelled.
+func (i *I18n) Watch(ctx context.Context) error {
+	if i.path == "" || i.fsys != nil {
+		return errors.New("instance was not created from a file on disk")
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// Watch the directory instead of the file as editors often save files by
+	// replacing them, which drops watches on the original file.
+	path := filepath.Clean(i.path)
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return err
+	}
+
+	go i.watch(ctx, w, path)
+	return nil
+}
+
+func (i *I18n) watch(ctx context.Context, w *fsnotify.Watcher, path string) {
+	defer w.Close()
+
+	t := time.NewTimer(watchDebounce)
+	t.Stop()
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(ev.Name) != path || !ev.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			t.Reset(watchDebounce)
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			i.reloaded(err)
+
+		case <-t.C:
+			i.reloaded(i.Reload())
+		}
+	}
+}
+
+// reloaded invokes the OnReload() callback, if there's one.
+func (i *I18n) reloaded(err error) {
+	i.mu.RLock()
+	fn := i.onReload
+	i.mu.RUnlock()
+
+	if fn != nil {
+		fn(err)
+	}
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "en.json")
+	if err := os.WriteFile(fpath, []byte(`{"_.code": "en", "_.name": "English", "foo": "Foo"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := NewFromFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloads := make(chan error, 10)
+	i.OnReload(func(err error) {
+		reloads <- err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := i.Watch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Multiple writes in quick succession should trigger a single reload.
+	for n := 0; n < 3; n++ {
+		if err := os.WriteFile(fpath, []byte(`{"_.code": "en", "_.name": "English", "foo": "Foo 2"}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case err := <-reloads:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+	assert(t, i.T("foo"), "Foo 2")
+
+	select {
+	case <-reloads:
+		t.Fatal("expected a single debounced reload")
+	case <-time.After(watchDebounce * 3):
+	}
+
+	n, _ := New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	if err := n.Watch(ctx); err == nil {
+		t.Fatal("expected error watching an instance not created from a file")
+	}
+}
@@ -0,0 +1,46 @@
+// Package yaml adds YAML language maps to i18n. Importing it registers its parser
+// for the .yml and .yaml files in i18n.NewFromGlob().
+package yaml
+
+import (
+	"github.com/knadh/go-i18n"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	i18n.RegisterParser(".yml", Parse)
+	i18n.RegisterParser(".yaml", Parse)
+}
+
+// New returns an I18n instance from the given YAML language map bytes.
+// Like i18n.New(), the map can either be flat or nested, and it should have
+// the _.code and _.name keys.
+func New(b []byte, opts ...i18n.Option) (*i18n.I18n, error) {
+	m, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return i18n.NewFromMap(m, opts...)
+}
+
+// Load loads a YAML language map into the instance overwriting
+// existing keys that conflict.
+func Load(i *i18n.I18n, b []byte) error {
+	m, err := Parse(b)
+	if err != nil {
+		return err
+	}
+
+	return i.LoadMap(m)
+}
+
+// Parse parses a flat or nested YAML language map. It's an i18n.Parser.
+func Parse(b []byte) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
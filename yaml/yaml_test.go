@@ -0,0 +1,79 @@
+package yaml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/knadh/go-i18n"
+)
+
+func assert(t *testing.T, a, v interface{}) {
+	t.Helper()
+	if fmt.Sprintf("%v", a) != fmt.Sprintf("%v", v) {
+		t.Fatalf("expected '%v', got '%v'", a, v)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	y := `
+# Meta.
+_.code: en
+_.name: English
+
+pageTitle: Welcome to the page
+globals:
+  message:
+    notFound: "{name} not found"
+page: Single page|Many pages
+count: 5
+ratio: 0.5
+`
+
+	i, err := New([]byte(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, i.Code(), "en")
+	assert(t, i.T("pageTitle"), "Welcome to the page")
+	assert(t, i.Ts("globals.message.notFound", "name", "Page"), "Page not found")
+	assert(t, i.Tc("page", 2), "Many pages")
+	assert(t, i.T("count"), "5")
+	assert(t, i.T("ratio"), "0.5")
+
+	if err := Load(i, []byte("foo: Foo\n")); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("foo"), "Foo")
+
+	if _, err := New([]byte("foo: Foo\n")); err == nil {
+		t.Fatal("expected error for missing meta keys")
+	}
+	if err := Load(i, []byte("foo: [a, b]\n")); err == nil {
+		t.Fatal("expected error for list value")
+	}
+}
+
+func TestNewFromGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json": `{"_.code": "en", "_.name": "English", "foo": "Foo"}`,
+		"b.yml":  "bar: Bar\n",
+		"c.YAML": "admin:\n  title: Admin\n",
+	}
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(b), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	i, err := i18n.NewFromGlob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, i.T("foo"), "Foo")
+	assert(t, i.T("bar"), "Bar")
+	assert(t, i.T("admin.title"), "Admin")
+}